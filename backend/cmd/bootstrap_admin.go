@@ -0,0 +1,31 @@
+package main
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/models"
+	"flag"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// runBootstrapAdmin promotes an existing account to admin, which is the only
+// way to get the first admin since roles are otherwise granted by admins.
+func runBootstrapAdmin(args []string) {
+	fs := flag.NewFlagSet("bootstrap-admin", flag.ExitOnError)
+	email := fs.String("email", "", "email of the account to promote")
+	fs.Parse(args)
+
+	if *email == "" {
+		log.Fatal("usage: bootstrap-admin -email user@example.com")
+	}
+
+	res := db.DB.Model(&models.User{}).Where("email = ?", *email).Updates(map[string]interface{}{"role": models.RoleAdmin, "version": gorm.Expr("version + 1")})
+	if res.Error != nil {
+		log.Fatalf("Ошибка назначения администратора: %v", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		log.Fatalf("Пользователь с email %s не найден", *email)
+	}
+	log.Printf("Пользователь %s назначен администратором", *email)
+}
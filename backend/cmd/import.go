@@ -0,0 +1,36 @@
+package main
+
+import (
+	"TalUpBackend/internal/content"
+	"TalUpBackend/internal/db"
+	"flag"
+	"log"
+)
+
+func runImportContent(args []string) {
+	fs := flag.NewFlagSet("import-content", flag.ExitOnError)
+	wordsPath := fs.String("words", "data/words.json", "path to the words JSON file")
+	sentencesPath := fs.String("sentences", "data/tasks_for_model.json", "path to the sentences JSON file")
+	fs.Parse(args)
+
+	words, sentences, err := content.ReadFiles(*wordsPath, *sentencesPath)
+	if err != nil {
+		log.Fatalf("Ошибка чтения контента: %v", err)
+	}
+	if err := content.Validate(words, sentences); err != nil {
+		log.Fatalf("Контент не прошёл проверку:\n%v", err)
+	}
+	for i, s := range sentences {
+		if err := content.CheckStrict(s); err != nil {
+			log.Printf("Внимание: предложение #%d: %v", i, err)
+		}
+	}
+	if err := content.Import(db.DB, words, sentences); err != nil {
+		log.Fatalf("Ошибка импорта контента: %v", err)
+	}
+	log.Printf("Импортировано слов: %d, предложений: %d", len(words), len(sentences))
+
+	if err := content.ValidateProgressLinks(db.DB); err != nil {
+		log.Printf("Внимание: прогресс ссылается на отсутствующие слова: %v", err)
+	}
+}
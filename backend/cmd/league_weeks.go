@@ -0,0 +1,36 @@
+package main
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/leaderboard"
+	"log"
+	"time"
+)
+
+// leagueWeekCheckInterval is how often the server looks for finished league
+// weeks. A week is closed within this long after it ends.
+const leagueWeekCheckInterval = 10 * time.Minute
+
+// runCloseLeagueWeeks settles every finished league week that is still open.
+func runCloseLeagueWeeks() {
+	n, err := leaderboard.CloseWeek(db.DB, time.Now())
+	if err != nil {
+		log.Fatalf("Ошибка подведения итогов недели лиг: %v", err)
+	}
+	log.Printf("Закрыто недель лиг: %d", n)
+}
+
+// closeLeagueWeeksEvery closes finished league weeks in the background, so
+// leaderboard reads never wait for a settlement. CloseWeek takes a database
+// lock, so several server instances may run it at once.
+func closeLeagueWeeksEvery(interval time.Duration) {
+	for {
+		n, err := leaderboard.CloseWeek(db.DB, time.Now())
+		if err != nil {
+			log.Printf("Ошибка подведения итогов недели лиг: %v", err)
+		} else if n > 0 {
+			log.Printf("Закрыто недель лиг: %d", n)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -1,17 +1,67 @@
 package main
 
 import (
+	"TalUpBackend/internal/auth"
+	"TalUpBackend/internal/config"
+	"TalUpBackend/internal/content"
 	"TalUpBackend/internal/db"
 	"TalUpBackend/internal/handlers"
 	"TalUpBackend/internal/middleware"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/migrations"
+	"flag"
 	"log"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	configPath := flag.String("config", "", "path to a YAML config file")
+	flag.Parse()
 
-	db.InitDB()
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Ошибка конфигурации: %v", err)
+	}
+
+	db.InitDB(cfg.Database.DSN)
+
+	switch flag.Arg(0) {
+	case "bootstrap-admin":
+		runBootstrapAdmin(flag.Args()[1:])
+		return
+	case "migrate":
+		runMigrate(flag.Args()[1:])
+		return
+	case "import-content":
+		runImportContent(flag.Args()[1:])
+		return
+	case "reconcile-wallets":
+		runReconcileWallets()
+		return
+	case "close-league-weeks":
+		runCloseLeagueWeeks()
+		return
+	}
+
+	pending, err := migrations.Pending(db.DB)
+	if err != nil {
+		log.Fatalf("Ошибка проверки миграций: %v", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("Схема базы данных устарела: %d миграций не применено. Выполните `migrate up`", len(pending))
+	}
+
+	tokens := auth.NewManager(cfg.Auth)
+	contentRepo := content.NewRepository(db.DB)
+	if err := contentRepo.Reload(); err != nil {
+		log.Fatalf("Ошибка загрузки контента: %v", err)
+	}
+	words, sentences := contentRepo.Counts()
+	log.Printf("Загружено слов: %d, заданий: %d", words, sentences)
+	handlers.Configure(cfg, tokens, contentRepo)
+	go closeLeagueWeeksEvery(leagueWeekCheckInterval)
 
 	r := gin.Default()
 
@@ -19,15 +69,24 @@ func main() {
 
 	r.POST("/auth/login", handlers.Login)
 
-	r.Static("/uploads", "./uploads")
+	r.POST("/auth/refresh", handlers.RefreshToken)
 
-	handlers.LoadTasks()
-	handlers.LoadBaseWords()
+	session := r.Group("/auth")
+	session.Use(middleware.AuthMiddleware(tokens))
+	{
+		session.POST("/logout", handlers.Logout)
+		session.POST("/logout-all", handlers.LogoutAll)
+	}
+
+	r.Static("/uploads", "./uploads")
 
 	authorized := r.Group("/api")
-	authorized.Use(middleware.AuthMiddleware())
+	authorized.Use(middleware.AuthMiddleware(tokens))
 	{
 		authorized.GET("/next-task", handlers.GetNextTask)
+		authorized.POST("/sessions", handlers.StartLearningSession)
+		authorized.GET("/sessions/:id", handlers.GetLearningSession)
+		authorized.POST("/sessions/:id/finish", handlers.FinishLearningSession)
 		authorized.POST("/submit-result", handlers.SubmitResult)
 		authorized.GET("/profile", handlers.GetProfile)
 		authorized.POST("/profile/update", handlers.UpdateProfile)
@@ -35,13 +94,57 @@ func main() {
 		authorized.PUT("/progress/update", handlers.UpdateProgress)
 		authorized.GET("/streak", handlers.GetStreak)
 		authorized.PUT("/streak/update", handlers.UpdateStreak)
+		authorized.GET("/streak/freezes", handlers.GetStreakFreezes)
 		authorized.GET("/leaderboard", handlers.GetLeaderboard)
+		authorized.GET("/leaderboard/history", handlers.GetLeagueHistory)
+		authorized.GET("/users/search", handlers.SearchUsers)
+		authorized.GET("/users/:id", handlers.GetUserProfile)
+		authorized.POST("/users/:id/follow", handlers.FollowUser)
+		authorized.DELETE("/users/:id/follow", handlers.UnfollowUser)
+		authorized.GET("/follows", handlers.GetFollows)
+		authorized.GET("/friends", handlers.GetFriends)
+		authorized.DELETE("/friends/:id", handlers.RemoveFriend)
+		authorized.GET("/friends/requests", handlers.GetFriendRequests)
+		authorized.POST("/friends/requests", handlers.SendFriendRequest)
+		authorized.POST("/friends/requests/:id/accept", handlers.AcceptFriendRequest)
+		authorized.POST("/friends/requests/:id/decline", handlers.DeclineFriendRequest)
 		authorized.PUT("/profile/update-password", handlers.UpdatePassword)
 		authorized.GET("/random-word", handlers.GetRandomWord)
 		authorized.GET("/word-list", handlers.GetWordList)
+		authorized.GET("/lives", handlers.GetLives)
 		authorized.POST("/shop/buy-life", handlers.BuyLife)
+		authorized.GET("/shop/items", handlers.ListShopItems)
+		authorized.POST("/shop/purchase", handlers.PurchaseItem)
+		authorized.GET("/wallet/history", handlers.GetWalletHistory)
+		authorized.GET("/achievements", handlers.GetAchievements)
+		authorized.GET("/goals", handlers.GetGoals)
+		authorized.PUT("/goals", handlers.UpdateGoal)
 		authorized.POST("/asr-submit", handlers.SubmitAsrResult)
 	}
 
-	log.Fatal(r.Run(":8080"))
+	admin := r.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(tokens))
+
+	editors := admin.Group("")
+	editors.Use(middleware.RequireRoles(models.RoleContentEditor, models.RoleAdmin))
+	{
+		editors.GET("/words", handlers.ListWords)
+		editors.POST("/words", handlers.CreateWord)
+		editors.PUT("/words/:id", handlers.UpdateWord)
+		editors.DELETE("/words/:id", handlers.DeleteWord)
+		editors.GET("/words/:id/sentences", handlers.ListWordSentences)
+		editors.POST("/sentences", handlers.CreateSentence)
+		editors.PUT("/sentences/:id", handlers.UpdateSentence)
+		editors.DELETE("/sentences/:id", handlers.DeleteSentence)
+		editors.POST("/content/reload", handlers.ReloadContent)
+	}
+
+	admins := admin.Group("")
+	admins.Use(middleware.RequireRoles(models.RoleAdmin))
+	{
+		admins.PUT("/users/:id/role", handlers.UpdateUserRole)
+		admins.GET("/users/:id/wallet/reconcile", handlers.ReconcileWallet)
+	}
+
+	log.Fatal(r.Run(cfg.Server.Addr))
 }
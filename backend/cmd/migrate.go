@@ -0,0 +1,56 @@
+package main
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/migrations"
+	"log"
+	"strconv"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := migrations.Up(db.DB)
+		if err != nil {
+			log.Fatalf("Ошибка миграции: %v", err)
+		}
+		log.Printf("Применено миграций: %d", n)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatal(migrateUsage)
+			}
+		}
+		n, err := migrations.Down(db.DB, steps)
+		if err != nil {
+			log.Fatalf("Ошибка отката миграции: %v", err)
+		}
+		log.Printf("Откачено миграций: %d", n)
+
+	case "status":
+		status, err := migrations.StatusOf(db.DB)
+		if err != nil {
+			log.Fatalf("Ошибка чтения миграций: %v", err)
+		}
+		for _, s := range status {
+			applied := "не применена"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			log.Printf("%04d_%s\t%s", s.Version, s.Name, applied)
+		}
+
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
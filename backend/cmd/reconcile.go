@@ -0,0 +1,37 @@
+package main
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/wallet"
+	"log"
+	"os"
+)
+
+// runReconcileWallets checks every user's balances against the ledger and
+// exits non-zero when any of them disagree.
+func runReconcileWallets() {
+	var users []models.User
+	if err := db.DB.Order("id").Find(&users).Error; err != nil {
+		log.Fatalf("Ошибка загрузки пользователей: %v", err)
+	}
+
+	bad := 0
+	for _, user := range users {
+		mismatches, err := wallet.Reconcile(db.DB, user)
+		if err != nil {
+			log.Fatalf("Ошибка сверки пользователя %d: %v", user.ID, err)
+		}
+		for _, m := range mismatches {
+			log.Printf("user %d: %s balance %d, ledger %d", user.ID, m.Currency, m.Balance, m.Ledger)
+		}
+		if len(mismatches) > 0 {
+			bad++
+		}
+	}
+
+	log.Printf("Проверено пользователей: %d, расхождений: %d", len(users), bad)
+	if bad > 0 {
+		os.Exit(1)
+	}
+}
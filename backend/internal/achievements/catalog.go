@@ -0,0 +1,115 @@
+package achievements
+
+import (
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/wallet"
+
+	"gorm.io/gorm"
+)
+
+// Event is a domain event that may move a metric. Evaluate only checks the
+// achievements whose metric listens to one of the events passed to it.
+type Event string
+
+const (
+	EventAnswerRecorded  Event = "answer_recorded"
+	EventReadingScored   Event = "reading_scored"
+	EventStreakExtended  Event = "streak_extended"
+	EventSessionFinished Event = "session_finished"
+)
+
+type Metric string
+
+const (
+	MetricStreak          Metric = "streak"
+	MetricLearnedWords    Metric = "learned_words"
+	MetricLevel           Metric = "level"
+	MetricPerfectReadings Metric = "perfect_readings"
+	MetricPerfectSessions Metric = "perfect_sessions"
+)
+
+// PerfectReadingScore is the pronunciation score of a reading without a
+// single wrong word.
+const PerfectReadingScore = 1.0
+
+type metric struct {
+	events []Event
+	value  func(tx *gorm.DB, user *models.User) (int, error)
+}
+
+var metrics = map[Metric]metric{
+	MetricStreak: {
+		events: []Event{EventStreakExtended},
+		value:  func(_ *gorm.DB, user *models.User) (int, error) { return user.StreakCount, nil },
+	},
+	MetricLearnedWords: {
+		events: []Event{EventAnswerRecorded},
+		value:  func(_ *gorm.DB, user *models.User) (int, error) { return user.LearnedWords, nil },
+	},
+	MetricLevel: {
+		events: []Event{EventAnswerRecorded, EventSessionFinished},
+		value:  func(_ *gorm.DB, user *models.User) (int, error) { return user.Level, nil },
+	},
+	MetricPerfectReadings: {
+		events: []Event{EventReadingScored},
+		value: func(tx *gorm.DB, user *models.User) (int, error) {
+			var n int64
+			err := tx.Model(&models.IssuedTask{}).
+				Where("user_id = ? AND type = ? AND asr_score >= ?", user.ID, "asr_reading", PerfectReadingScore).
+				Count(&n).Error
+			return int(n), err
+		},
+	},
+	MetricPerfectSessions: {
+		events: []Event{EventSessionFinished},
+		value: func(tx *gorm.DB, user *models.User) (int, error) {
+			var n int64
+			err := tx.Model(&models.LearningSession{}).
+				Where("user_id = ? AND status = ? AND task_count > 0 AND correct = task_count", user.ID, models.SessionFinished).
+				Count(&n).Error
+			return int(n), err
+		},
+	},
+}
+
+type Reward struct {
+	Currency string `json:"currency"`
+	Amount   int    `json:"amount"`
+}
+
+// Achievement unlocks once Metric reaches Goal and pays Rewards once.
+type Achievement struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Metric      Metric   `json:"metric"`
+	Goal        int      `json:"goal"`
+	Rewards     []Reward `json:"rewards"`
+}
+
+// Catalog lists every achievement in display order. IDs are stored with
+// unlocks and must never be reused.
+var Catalog = []Achievement{
+	{ID: "streak_3", Name: "Три дня подряд", Description: "Занимайтесь 3 дня подряд",
+		Metric: MetricStreak, Goal: 3, Rewards: []Reward{{wallet.Coins, 5}}},
+	{ID: "streak_7", Name: "Неделя без пропусков", Description: "Занимайтесь 7 дней подряд",
+		Metric: MetricStreak, Goal: 7, Rewards: []Reward{{wallet.Coins, 10}, {wallet.BonusLives, 1}}},
+	{ID: "streak_30", Name: "Месяц практики", Description: "Занимайтесь 30 дней подряд",
+		Metric: MetricStreak, Goal: 30, Rewards: []Reward{{wallet.Coins, 50}}},
+	{ID: "words_10", Name: "Первые слова", Description: "Выучите 10 слов",
+		Metric: MetricLearnedWords, Goal: 10, Rewards: []Reward{{wallet.Coins, 5}}},
+	{ID: "words_100", Name: "Сотня слов", Description: "Выучите 100 слов",
+		Metric: MetricLearnedWords, Goal: 100, Rewards: []Reward{{wallet.Coins, 30}}},
+	{ID: "words_500", Name: "Словарный запас", Description: "Выучите 500 слов",
+		Metric: MetricLearnedWords, Goal: 500, Rewards: []Reward{{wallet.Coins, 100}}},
+	{ID: "level_5", Name: "Пятый уровень", Description: "Достигните 5 уровня",
+		Metric: MetricLevel, Goal: 5, Rewards: []Reward{{wallet.Coins, 10}}},
+	{ID: "reading_perfect_1", Name: "Идеальное чтение", Description: "Прочитайте предложение без единой ошибки",
+		Metric: MetricPerfectReadings, Goal: 1, Rewards: []Reward{{wallet.Coins, 5}}},
+	{ID: "reading_perfect_25", Name: "Чистое произношение", Description: "Прочитайте без ошибок 25 предложений",
+		Metric: MetricPerfectReadings, Goal: 25, Rewards: []Reward{{wallet.Coins, 25}}},
+	{ID: "session_perfect_1", Name: "Урок без ошибок", Description: "Пройдите урок без единой ошибки",
+		Metric: MetricPerfectSessions, Goal: 1, Rewards: []Reward{{wallet.Coins, 5}}},
+	{ID: "session_perfect_10", Name: "Отличник", Description: "Пройдите без ошибок 10 уроков",
+		Metric: MetricPerfectSessions, Goal: 10, Rewards: []Reward{{wallet.Coins, 20}}},
+}
@@ -0,0 +1,111 @@
+package achievements
+
+import (
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/wallet"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Evaluate unlocks every achievement reached after the given events and
+// pays its rewards into ledger. It must run under the user's row lock,
+// inside the transaction that saves the user, e.g. from updateUser. It
+// returns the achievements unlocked by this call.
+func Evaluate(tx *gorm.DB, user *models.User, ledger *wallet.Ledger, events ...Event) ([]Achievement, error) {
+	unlocked, err := unlockedIDs(tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[Metric]int)
+	var out []Achievement
+	for _, a := range Catalog {
+		if _, done := unlocked[a.ID]; done || !listens(metrics[a.Metric], events) {
+			continue
+		}
+		v, ok := values[a.Metric]
+		if !ok {
+			if v, err = metrics[a.Metric].value(tx, user); err != nil {
+				return nil, err
+			}
+			values[a.Metric] = v
+		}
+		if v < a.Goal {
+			continue
+		}
+
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserAchievement{UserID: user.ID, AchievementID: a.ID, UnlockedAt: time.Now()})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		src := wallet.Source{Type: "achievement", ID: a.ID}
+		for _, r := range a.Rewards {
+			ledger.Add(r.Currency, r.Amount, wallet.ReasonAchievement, src)
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// Progress is an achievement together with how far the user is toward it.
+type Progress struct {
+	Achievement
+	Value      int        `json:"value"`
+	Unlocked   bool       `json:"unlocked"`
+	UnlockedAt *time.Time `json:"unlockedAt"`
+}
+
+// List returns the user's progress toward every achievement in catalog
+// order. Value is capped at the goal.
+func List(db *gorm.DB, user *models.User) ([]Progress, error) {
+	unlocked, err := unlockedIDs(db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[Metric]int, len(metrics))
+	for name, m := range metrics {
+		if values[name], err = m.value(db, user); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]Progress, 0, len(Catalog))
+	for _, a := range Catalog {
+		p := Progress{Achievement: a, Value: min(values[a.Metric], a.Goal)}
+		if at, ok := unlocked[a.ID]; ok {
+			p.Unlocked, p.UnlockedAt, p.Value = true, &at, a.Goal
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func unlockedIDs(db *gorm.DB, userID uint) (map[string]time.Time, error) {
+	var rows []models.UserAchievement
+	if err := db.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]time.Time, len(rows))
+	for _, r := range rows {
+		out[r.AchievementID] = r.UnlockedAt
+	}
+	return out, nil
+}
+
+func listens(m metric, events []Event) bool {
+	for _, want := range m.events {
+		for _, e := range events {
+			if e == want {
+				return true
+			}
+		}
+	}
+	return false
+}
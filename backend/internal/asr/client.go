@@ -0,0 +1,43 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+var (
+	ErrUnavailable = errors.New("speech recognizer unavailable")
+	ErrTimeout     = errors.New("speech recognizer timed out")
+	ErrBadResponse = errors.New("speech recognizer returned an invalid response")
+)
+
+type Client interface {
+	Transcribe(ctx context.Context, filename string, audio []byte) (string, error)
+}
+
+// Stub returns a fixed transcript. It backs the "stub" backend for running
+// without the whisper service.
+type Stub struct {
+	Text string
+}
+
+func (s Stub) Transcribe(ctx context.Context, filename string, audio []byte) (string, error) {
+	return s.Text, nil
+}
+
+// Fake returns a fixed transcript or error and counts the calls it received.
+// It is meant for tests and is safe for concurrent use.
+type Fake struct {
+	Text  string
+	Err   error
+	Calls atomic.Int64
+}
+
+func (f *Fake) Transcribe(ctx context.Context, filename string, audio []byte) (string, error) {
+	f.Calls.Add(1)
+	if f.Err != nil {
+		return "", f.Err
+	}
+	return f.Text, nil
+}
@@ -0,0 +1,111 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type HTTPClient struct {
+	url     string
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+	client  *http.Client
+}
+
+func NewHTTPClient(url string, timeout time.Duration, retries int) *HTTPClient {
+	return &HTTPClient{
+		url:     url,
+		timeout: timeout,
+		retries: retries,
+		backoff: 300 * time.Millisecond,
+		client:  &http.Client{},
+	}
+}
+
+func (c *HTTPClient) Transcribe(ctx context.Context, filename string, audio []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return "", ErrTimeout
+			case <-time.After(c.backoff * time.Duration(attempt)):
+			}
+		}
+
+		text, retry, err := c.attempt(ctx, filename, audio)
+		if err == nil {
+			return text, nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	if ctx.Err() != nil {
+		return "", ErrTimeout
+	}
+	return "", lastErr
+}
+
+// attempt sends the audio once. retry reports whether the failure looks
+// transient: network errors and 5xx responses are retried, the rest is not.
+func (c *HTTPClient) attempt(ctx context.Context, filename string, audio []byte) (text string, retry bool, err error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := part.Write(audio); err != nil {
+		return "", false, err
+	}
+	if err := writer.Close(); err != nil {
+		return "", false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, body)
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "", false, ErrTimeout
+		}
+		return "", true, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return "", true, fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("%w: status %d", ErrBadResponse, resp.StatusCode)
+	}
+
+	var result struct {
+		Text *string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", false, fmt.Errorf("%w: %v", ErrBadResponse, err)
+	}
+	if result.Text == nil {
+		return "", false, fmt.Errorf("%w: missing text", ErrBadResponse)
+	}
+	return strings.TrimSpace(*result.Text), false, nil
+}
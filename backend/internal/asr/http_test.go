@@ -0,0 +1,85 @@
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// whisper serves transcripts from fake, failing the first failures requests
+// with status.
+func whisper(t *testing.T, fake *Fake, failures int64, status int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("request has no audio file: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		file.Close()
+
+		text, err := fake.Transcribe(r.Context(), header.Filename, nil)
+		if err != nil || fake.Calls.Load() <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"text": " " + text + " "})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPClientRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int64
+		status    int
+		retries   int
+		want      string
+		wantErr   error
+		wantCalls int64
+	}{
+		{name: "first attempt", retries: 2, want: "сәлем", wantCalls: 1},
+		{name: "recovers after 5xx", failures: 2, status: http.StatusServiceUnavailable, retries: 2, want: "сәлем", wantCalls: 3},
+		{name: "gives up after retries", failures: 10, status: http.StatusBadGateway, retries: 2, wantErr: ErrUnavailable, wantCalls: 3},
+		{name: "4xx is not retried", failures: 10, status: http.StatusBadRequest, retries: 2, wantErr: ErrBadResponse, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &Fake{Text: "сәлем"}
+			srv := whisper(t, fake, tt.failures, tt.status)
+			c := NewHTTPClient(srv.URL, 5*time.Second, tt.retries)
+			c.backoff = time.Millisecond
+
+			got, err := c.Transcribe(context.Background(), "audio.wav", []byte("RIFF"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+			if n := fake.Calls.Load(); n != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHTTPClientTimeout(t *testing.T) {
+	fake := &Fake{Text: "сәлем"}
+	srv := whisper(t, fake, 10, http.StatusServiceUnavailable)
+	c := NewHTTPClient(srv.URL, 50*time.Millisecond, 5)
+	c.backoff = 40 * time.Millisecond
+
+	if _, err := c.Transcribe(context.Background(), "audio.wav", []byte("RIFF")); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want %v", err, ErrTimeout)
+	}
+	if n := fake.Calls.Load(); n >= 6 {
+		t.Errorf("calls = %d, retries should stop at the deadline", n)
+	}
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"TalUpBackend/internal/config"
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+	ErrTokenReused  = errors.New("refresh token reuse detected")
+)
+
+type Claims struct {
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int
+}
+
+type Manager struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewManager(cfg config.AuthConfig) *Manager {
+	return &Manager{
+		secret:     []byte(cfg.JWTSecret),
+		accessTTL:  cfg.AccessTTL,
+		refreshTTL: cfg.RefreshTTL,
+	}
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *Manager) signAccess(userID uint, sessionID string, now time.Time) (string, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	claims := Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessTTL)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+}
+
+func (m *Manager) issue(tx *gorm.DB, userID uint, familyID string, now time.Time) (TokenPair, error) {
+	raw, err := randomToken(32)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	row := models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: now.Add(m.refreshTTL),
+	}
+	if err := tx.Create(&row).Error; err != nil {
+		return TokenPair{}, err
+	}
+
+	access, err := m.signAccess(userID, familyID, now)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: raw,
+		ExpiresIn:    int(m.accessTTL.Seconds()),
+	}, nil
+}
+
+// StartSession opens a new refresh-token family, one per signed-in device.
+func (m *Manager) StartSession(userID uint) (TokenPair, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return m.issue(db.DB, userID, familyID, time.Now())
+}
+
+// Refresh rotates a refresh token. Presenting a token that was already
+// rotated means it leaked, so the whole family is revoked.
+func (m *Manager) Refresh(raw string) (TokenPair, uint, error) {
+	now := time.Now()
+
+	var current models.RefreshToken
+	if err := db.DB.Where("token_hash = ?", hashToken(raw)).First(&current).Error; err != nil {
+		return TokenPair{}, 0, ErrInvalidToken
+	}
+	if current.RevokedAt != nil {
+		m.revokeFamily(current.FamilyID, now)
+		return TokenPair{}, current.UserID, ErrTokenReused
+	}
+	if now.After(current.ExpiresAt) {
+		return TokenPair{}, current.UserID, ErrTokenExpired
+	}
+
+	var pair TokenPair
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Update("revoked_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrTokenReused
+		}
+		var err error
+		pair, err = m.issue(tx, current.UserID, current.FamilyID, now)
+		return err
+	})
+	if errors.Is(err, ErrTokenReused) {
+		m.revokeFamily(current.FamilyID, now)
+	}
+	return pair, current.UserID, err
+}
+
+func (m *Manager) ParseAccess(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return m.secret, nil
+	})
+	if err != nil {
+		var ve *jwt.ValidationError
+		if errors.As(err, &ve) && ve.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrInvalidToken
+	}
+	if !token.Valid || claims.UserID == 0 || claims.SessionID == "" || claims.ExpiresAt == nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// SessionActive reports whether the device session behind an access token
+// still has a live refresh token, i.e. it was not logged out and has not
+// expired.
+func (m *Manager) SessionActive(userID uint, sessionID string) bool {
+	var count int64
+	db.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, sessionID, time.Now()).
+		Count(&count)
+	return count > 0
+}
+
+func (m *Manager) RevokeSession(userID uint, sessionID string) error {
+	return db.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (m *Manager) RevokeAll(userID uint) error {
+	return db.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (m *Manager) revokeFamily(familyID string, now time.Time) {
+	db.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now)
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Models   ModelsConfig   `yaml:"models"`
+
+	Pronunciation PronunciationConfig `yaml:"pronunciation"`
+	Lives         LivesConfig         `yaml:"lives"`
+}
+
+type ServerConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+type DatabaseConfig struct {
+	DSN string `yaml:"dsn"`
+}
+
+type AuthConfig struct {
+	JWTSecret  string        `yaml:"jwt_secret"`
+	AccessTTL  time.Duration `yaml:"access_ttl"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+}
+
+type ModelsConfig struct {
+	DistractorURL       string        `yaml:"distractor_url"`
+	DistractorTimeout   time.Duration `yaml:"distractor_timeout"`
+	DistractorCacheSize int           `yaml:"distractor_cache_size"`
+	TranscribeBackend   string        `yaml:"transcribe_backend"`
+	TranscribeURL       string        `yaml:"transcribe_url"`
+	TranscribeTimeout   time.Duration `yaml:"transcribe_timeout"`
+	TranscribeRetries   int           `yaml:"transcribe_retries"`
+	TranscribeStubText  string        `yaml:"transcribe_stub_text"`
+}
+
+type PronunciationConfig struct {
+	Thresholds       map[string]float64 `yaml:"thresholds"`
+	DefaultThreshold float64            `yaml:"default_threshold"`
+}
+
+// Threshold returns the minimum reading score for a task difficulty.
+func (p PronunciationConfig) Threshold(difficulty string) float64 {
+	if t, ok := p.Thresholds[difficulty]; ok {
+		return t
+	}
+	return p.DefaultThreshold
+}
+
+// LivesConfig caps regular lives and sets how often one regenerates.
+type LivesConfig struct {
+	Max           int           `yaml:"max"`
+	RegenInterval time.Duration `yaml:"regen_interval"`
+}
+
+const minSecretLength = 16
+
+func defaults() Config {
+	return Config{
+		Server: ServerConfig{Addr: ":8080"},
+		Auth: AuthConfig{
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 30 * 24 * time.Hour,
+		},
+		Models: ModelsConfig{
+			DistractorURL:       "http://127.0.0.1:8000/predict/",
+			DistractorTimeout:   3 * time.Second,
+			DistractorCacheSize: 2048,
+			TranscribeBackend:   "whisper",
+			TranscribeURL:       "http://127.0.0.1:8001/transcribe",
+			TranscribeTimeout:   20 * time.Second,
+			TranscribeRetries:   2,
+		},
+		Pronunciation: PronunciationConfig{
+			Thresholds:       map[string]float64{"A": 0.7, "B": 0.75, "C": 0.8},
+			DefaultThreshold: 0.75,
+		},
+		Lives: LivesConfig{
+			Max:           5,
+			RegenInterval: 15 * time.Minute,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, then the optional YAML file at
+// path (or TALUP_CONFIG when path is empty), then TALUP_* environment
+// variables, and validates the result.
+func Load(path string) (Config, error) {
+	cfg := defaults()
+
+	if path == "" {
+		path = os.Getenv("TALUP_CONFIG")
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("config: read %s: %w", path, err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("config: parse %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return cfg, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func applyEnv(cfg *Config) error {
+	overrides := map[string]*string{
+		"TALUP_ADDR":                 &cfg.Server.Addr,
+		"TALUP_DATABASE_DSN":         &cfg.Database.DSN,
+		"TALUP_JWT_SECRET":           &cfg.Auth.JWTSecret,
+		"TALUP_DISTRACTOR_URL":       &cfg.Models.DistractorURL,
+		"TALUP_TRANSCRIBE_URL":       &cfg.Models.TranscribeURL,
+		"TALUP_TRANSCRIBE_BACKEND":   &cfg.Models.TranscribeBackend,
+		"TALUP_TRANSCRIBE_STUB_TEXT": &cfg.Models.TranscribeStubText,
+	}
+	for name, field := range overrides {
+		if v, ok := os.LookupEnv(name); ok {
+			*field = strings.TrimSpace(v)
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"TALUP_ACCESS_TTL":          &cfg.Auth.AccessTTL,
+		"TALUP_REFRESH_TTL":         &cfg.Auth.RefreshTTL,
+		"TALUP_DISTRACTOR_TIMEOUT":  &cfg.Models.DistractorTimeout,
+		"TALUP_TRANSCRIBE_TIMEOUT":  &cfg.Models.TranscribeTimeout,
+		"TALUP_LIFE_REGEN_INTERVAL": &cfg.Lives.RegenInterval,
+	}
+	for name, field := range durations {
+		if v, ok := os.LookupEnv(name); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(v))
+			if err != nil {
+				return fmt.Errorf("config: %s: %w", name, err)
+			}
+			*field = d
+		}
+	}
+	return nil
+}
+
+func (cfg Config) Validate() error {
+	var errs []error
+
+	if cfg.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr (TALUP_ADDR) is required"))
+	}
+	if cfg.Database.DSN == "" {
+		errs = append(errs, errors.New("database.dsn (TALUP_DATABASE_DSN) is required"))
+	}
+	if len(cfg.Auth.JWTSecret) < minSecretLength {
+		errs = append(errs, fmt.Errorf("auth.jwt_secret (TALUP_JWT_SECRET) must be at least %d characters", minSecretLength))
+	}
+	if cfg.Auth.AccessTTL <= 0 {
+		errs = append(errs, errors.New("auth.access_ttl (TALUP_ACCESS_TTL) must be positive"))
+	}
+	if cfg.Auth.RefreshTTL <= cfg.Auth.AccessTTL {
+		errs = append(errs, errors.New("auth.refresh_ttl (TALUP_REFRESH_TTL) must be longer than auth.access_ttl"))
+	}
+	if err := validateURL(cfg.Models.DistractorURL); err != nil {
+		errs = append(errs, fmt.Errorf("models.distractor_url (TALUP_DISTRACTOR_URL): %w", err))
+	}
+	if cfg.Models.DistractorTimeout <= 0 {
+		errs = append(errs, errors.New("models.distractor_timeout (TALUP_DISTRACTOR_TIMEOUT) must be positive"))
+	}
+	if cfg.Models.DistractorCacheSize <= 0 {
+		errs = append(errs, errors.New("models.distractor_cache_size must be positive"))
+	}
+	switch cfg.Models.TranscribeBackend {
+	case "whisper":
+		if err := validateURL(cfg.Models.TranscribeURL); err != nil {
+			errs = append(errs, fmt.Errorf("models.transcribe_url (TALUP_TRANSCRIBE_URL): %w", err))
+		}
+	case "stub":
+	default:
+		errs = append(errs, fmt.Errorf("models.transcribe_backend (TALUP_TRANSCRIBE_BACKEND): unknown backend %q, want whisper or stub", cfg.Models.TranscribeBackend))
+	}
+	if cfg.Models.TranscribeTimeout <= 0 {
+		errs = append(errs, errors.New("models.transcribe_timeout (TALUP_TRANSCRIBE_TIMEOUT) must be positive"))
+	}
+	if cfg.Models.TranscribeRetries < 0 {
+		errs = append(errs, errors.New("models.transcribe_retries must not be negative"))
+	}
+
+	if t := cfg.Pronunciation.DefaultThreshold; t <= 0 || t > 1 {
+		errs = append(errs, errors.New("pronunciation.default_threshold must be in (0, 1]"))
+	}
+	for difficulty, t := range cfg.Pronunciation.Thresholds {
+		if t <= 0 || t > 1 {
+			errs = append(errs, fmt.Errorf("pronunciation.thresholds.%s must be in (0, 1]", difficulty))
+		}
+	}
+
+	if cfg.Lives.Max <= 0 {
+		errs = append(errs, errors.New("lives.max must be positive"))
+	}
+	if cfg.Lives.RegenInterval <= 0 {
+		errs = append(errs, errors.New("lives.regen_interval (TALUP_LIFE_REGEN_INTERVAL) must be positive"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("host is missing")
+	}
+	return nil
+}
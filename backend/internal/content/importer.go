@@ -0,0 +1,160 @@
+package content
+
+import (
+	"TalUpBackend/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var Difficulties = map[string]bool{"A": true, "B": true, "C": true}
+
+const Mask = "<mask>"
+
+type wordFile struct {
+	ID   uint   `json:"id"`
+	Word string `json:"word"`
+}
+
+func ReadFiles(wordsPath, sentencesPath string) ([]models.Word, []models.Sentence, error) {
+	wordsData, err := os.ReadFile(wordsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	sentencesData, err := os.ReadFile(sentencesPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rawWords []wordFile
+	if err := json.Unmarshal(wordsData, &rawWords); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", wordsPath, err)
+	}
+	var sentences []models.Sentence
+	if err := json.Unmarshal(sentencesData, &sentences); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", sentencesPath, err)
+	}
+
+	words := make([]models.Word, 0, len(rawWords))
+	for _, w := range rawWords {
+		words = append(words, models.Word{ID: w.ID, Word: strings.TrimSpace(w.Word)})
+	}
+	return words, sentences, nil
+}
+
+func ValidateSentence(s models.Sentence) error {
+	var errs []error
+	if !strings.Contains(s.MaskedSentence, Mask) {
+		errs = append(errs, fmt.Errorf("masked_sentence has no %s", Mask))
+	}
+	answer := strings.TrimSpace(s.CorrectAnswer)
+	if answer == "" {
+		errs = append(errs, errors.New("correct_answer is empty"))
+	} else if strings.Contains(answer, Mask) {
+		errs = append(errs, fmt.Errorf("correct_answer must not contain %s", Mask))
+	}
+
+	if !Difficulties[s.Difficulty] {
+		errs = append(errs, fmt.Errorf("difficulty %q is not one of A, B, C", s.Difficulty))
+	}
+	return errors.Join(errs...)
+}
+
+// CheckStrict holds a sentence to the rules for newly edited content: one
+// mask, and masked_sentence filled with correct_answer equal to text. Some
+// rows of the shipped JSON break them, so the importer only warns while the
+// admin API rejects.
+func CheckStrict(s models.Sentence) error {
+	if n := strings.Count(s.MaskedSentence, Mask); n != 1 {
+		return fmt.Errorf("masked_sentence must contain %s exactly once, found %d", Mask, n)
+	}
+	if s.Text == "" {
+		return nil
+	}
+	filled := strings.Replace(s.MaskedSentence, Mask, strings.TrimSpace(s.CorrectAnswer), 1)
+	if !strings.EqualFold(strings.TrimSpace(filled), strings.TrimSpace(s.Text)) {
+		return fmt.Errorf("masked_sentence filled with correct_answer %q does not match text %q", s.CorrectAnswer, s.Text)
+	}
+	return nil
+}
+
+// Validate checks the whole content set and reports every problem at once.
+func Validate(words []models.Word, sentences []models.Sentence) error {
+	var errs []error
+
+	ids := make(map[uint]bool, len(words))
+	for i, w := range words {
+		switch {
+		case w.ID == 0:
+			errs = append(errs, fmt.Errorf("word #%d: id is missing", i))
+		case ids[w.ID]:
+			errs = append(errs, fmt.Errorf("word #%d: duplicate id %d", i, w.ID))
+		}
+		if w.Word == "" {
+			errs = append(errs, fmt.Errorf("word #%d: word is empty", i))
+		}
+		ids[w.ID] = true
+	}
+
+	for i, s := range sentences {
+		if err := ValidateSentence(s); err != nil {
+			errs = append(errs, fmt.Errorf("sentence #%d: %w", i, err))
+		}
+		if !ids[s.WordID] {
+			errs = append(errs, fmt.Errorf("sentence #%d: word_id %d does not exist", i, s.WordID))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Import upserts the words and replaces the sentences of every imported
+// word in one transaction.
+func Import(db *gorm.DB, words []models.Word, sentences []models.Sentence) error {
+	translations := map[uint]string{}
+	for _, s := range sentences {
+		if _, ok := translations[s.WordID]; !ok && s.TranslationTarget != "" {
+			translations[s.WordID] = s.TranslationTarget
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		wordIDs := make([]uint, 0, len(words))
+		for i := range words {
+			if words[i].Translation == "" {
+				words[i].Translation = translations[words[i].ID]
+			}
+			wordIDs = append(wordIDs, words[i].ID)
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"word", "translation"}),
+		}).Create(&words).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("SELECT setval(pg_get_serial_sequence('words', 'id'), (SELECT MAX(id) FROM words))").Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("word_id IN ?", wordIDs).Delete(&models.Sentence{}).Error; err != nil {
+			return err
+		}
+		for i := range sentences {
+			sentences[i].ID = 0
+		}
+		return tx.CreateInBatches(&sentences, 200).Error
+	})
+}
+
+// ValidateProgressLinks turns on checking of user_words.word_id for rows that
+// existed before the words table. It fails while any progress row points to
+// a word that was never imported.
+func ValidateProgressLinks(db *gorm.DB) error {
+	return db.Exec("ALTER TABLE user_words VALIDATE CONSTRAINT fk_user_words_word").Error
+}
@@ -0,0 +1,128 @@
+package content
+
+import (
+	"TalUpBackend/internal/models"
+	"strings"
+	"testing"
+)
+
+func sentence(wordID uint) models.Sentence {
+	return models.Sentence{
+		WordID:         wordID,
+		Text:           "Мен кітап оқимын",
+		MaskedSentence: "Мен <mask> оқимын",
+		CorrectAnswer:  "кітап",
+		Difficulty:     "A",
+	}
+}
+
+func TestValidate(t *testing.T) {
+	words := []models.Word{{ID: 1, Word: "кітап"}, {ID: 2, Word: "үй"}}
+
+	tests := []struct {
+		name      string
+		words     []models.Word
+		sentences func() []models.Sentence
+		wantErrs  []string
+	}{
+		{
+			name:      "valid",
+			words:     words,
+			sentences: func() []models.Sentence { return []models.Sentence{sentence(1), sentence(2)} },
+		},
+		{
+			name:      "word problems",
+			words:     []models.Word{{ID: 1, Word: "кітап"}, {ID: 1, Word: "үй"}, {Word: ""}},
+			sentences: func() []models.Sentence { return nil },
+			wantErrs: []string{
+				"word #1: duplicate id 1",
+				"word #2: id is missing",
+				"word #2: word is empty",
+			},
+		},
+		{
+			name:  "unknown word",
+			words: words,
+			sentences: func() []models.Sentence {
+				return []models.Sentence{sentence(3)}
+			},
+			wantErrs: []string{"sentence #0: word_id 3 does not exist"},
+		},
+		{
+			name:  "sentence problems are all reported",
+			words: words,
+			sentences: func() []models.Sentence {
+				s := sentence(1)
+				s.MaskedSentence = "Мен кітап оқимын"
+				s.CorrectAnswer = "  "
+				s.Difficulty = "D"
+				m := sentence(2)
+				m.CorrectAnswer = "<mask>"
+				return []models.Sentence{s, m}
+			},
+			wantErrs: []string{
+				"sentence #0: masked_sentence has no <mask>",
+				"correct_answer is empty",
+				`difficulty "D" is not one of A, B, C`,
+				"sentence #1: correct_answer must not contain <mask>",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.words, tt.sentences())
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("err = nil, want %q", tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("err = %q, missing %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(*models.Sentence)
+		wantErr string
+	}{
+		{name: "valid", edit: func(*models.Sentence) {}},
+		{name: "no text to compare", edit: func(s *models.Sentence) { s.Text = "" }},
+		{name: "case and padding are ignored", edit: func(s *models.Sentence) { s.Text = " мен кітап оқимын " }},
+		{
+			name:    "two masks",
+			edit:    func(s *models.Sentence) { s.MaskedSentence = "<mask> <mask> оқимын" },
+			wantErr: "exactly once, found 2",
+		},
+		{
+			name:    "answer does not fill the text",
+			edit:    func(s *models.Sentence) { s.CorrectAnswer = "үй" },
+			wantErr: "does not match text",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := sentence(1)
+			tt.edit(&s)
+			err := CheckStrict(s)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
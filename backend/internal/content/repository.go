@@ -0,0 +1,65 @@
+package content
+
+import (
+	"TalUpBackend/internal/models"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Repository serves words and sentences from an in-memory snapshot of the
+// database. Reload swaps in a fresh snapshot without blocking readers for
+// longer than the swap itself.
+type Repository struct {
+	db *gorm.DB
+
+	mu        sync.RWMutex
+	words     map[uint]models.Word
+	sentences []models.Sentence
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db, words: map[uint]models.Word{}}
+}
+
+func (r *Repository) Reload() error {
+	var words []models.Word
+	if err := r.db.Order("id").Find(&words).Error; err != nil {
+		return err
+	}
+	var sentences []models.Sentence
+	if err := r.db.Order("id").Find(&sentences).Error; err != nil {
+		return err
+	}
+
+	byID := make(map[uint]models.Word, len(words))
+	for _, w := range words {
+		byID[w.ID] = w
+	}
+
+	r.mu.Lock()
+	r.words = byID
+	r.sentences = sentences
+	r.mu.Unlock()
+	return nil
+}
+
+// Sentences returns the current snapshot. Callers must not modify it.
+func (r *Repository) Sentences() []models.Sentence {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sentences
+}
+
+func (r *Repository) Word(id uint) (models.Word, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, ok := r.words[id]
+	return w, ok
+}
+
+func (r *Repository) Counts() (words, sentences int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.words), len(r.sentences)
+}
@@ -0,0 +1,84 @@
+package distractors
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+type cacheEntry struct {
+	key   string
+	words []string
+}
+
+// CachedProvider is an LRU cache in front of another provider, keyed by the
+// masked sentence. Only successful lookups are cached.
+type CachedProvider struct {
+	next     Provider
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func NewCachedProvider(next Provider, capacity int) *CachedProvider {
+	return &CachedProvider{
+		next:     next,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (p *CachedProvider) Distractors(ctx context.Context, req Request) ([]string, error) {
+	if words, ok := p.get(req.MaskedSentence); ok {
+		return words, nil
+	}
+
+	words, err := p.next.Distractors(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	p.put(req.MaskedSentence, words)
+	return append([]string(nil), words...), nil
+}
+
+func (p *CachedProvider) get(key string) ([]string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	el, ok := p.entries[key]
+	if !ok {
+		return nil, false
+	}
+	p.order.MoveToFront(el)
+	return append([]string(nil), el.Value.(*cacheEntry).words...), true
+}
+
+func (p *CachedProvider) put(key string, words []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if el, ok := p.entries[key]; ok {
+		el.Value.(*cacheEntry).words = words
+		p.order.MoveToFront(el)
+		return
+	}
+
+	p.entries[key] = p.order.PushFront(&cacheEntry{key: key, words: words})
+	for p.order.Len() > p.capacity {
+		oldest := p.order.Back()
+		p.order.Remove(oldest)
+		delete(p.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Purge drops every cached entry, e.g. after the content set changes.
+func (p *CachedProvider) Purge() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.order.Init()
+	p.entries = make(map[string]*list.Element)
+}
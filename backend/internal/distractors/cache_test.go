@@ -0,0 +1,73 @@
+package distractors
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCachedProvider(t *testing.T) {
+	ctx := context.Background()
+	next := &fake{words: []string{"үй", "қала"}}
+	p := NewCachedProvider(next, 2)
+	a := Request{MaskedSentence: "a <mask>"}
+	b := Request{MaskedSentence: "b <mask>"}
+	c := Request{MaskedSentence: "c <mask>"}
+
+	for _, req := range []Request{a, a, b, a} {
+		if _, err := p.Distractors(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if next.calls != 2 {
+		t.Fatalf("calls = %d after a, a, b, a; want 2", next.calls)
+	}
+
+	// c evicts b, the least recently used entry.
+	p.Distractors(ctx, c)
+	p.Distractors(ctx, a)
+	if next.calls != 3 {
+		t.Errorf("calls = %d, a should still be cached", next.calls)
+	}
+	p.Distractors(ctx, b)
+	if next.calls != 4 {
+		t.Errorf("calls = %d, b should have been evicted", next.calls)
+	}
+
+	p.Purge()
+	p.Distractors(ctx, a)
+	if next.calls != 5 {
+		t.Errorf("calls = %d, purge should drop every entry", next.calls)
+	}
+}
+
+func TestCachedProviderSkipsErrors(t *testing.T) {
+	ctx := context.Background()
+	next := &fake{err: errors.New("model is down")}
+	p := NewCachedProvider(next, 10)
+	req := Request{MaskedSentence: "a <mask>"}
+
+	p.Distractors(ctx, req)
+	next.err = nil
+	next.words = []string{"үй"}
+	got, err := p.Distractors(ctx, req)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("got %q, %v after the model recovered", got, err)
+	}
+	if next.calls != 2 {
+		t.Errorf("calls = %d, a failed lookup must not be cached", next.calls)
+	}
+}
+
+func TestCachedProviderCopies(t *testing.T) {
+	ctx := context.Background()
+	p := NewCachedProvider(&fake{words: []string{"үй"}}, 10)
+	req := Request{MaskedSentence: "a <mask>"}
+
+	got, _ := p.Distractors(ctx, req)
+	got[0] = "changed"
+	again, _ := p.Distractors(ctx, req)
+	if again[0] != "үй" {
+		t.Errorf("cached words = %q, callers must not share the cached slice", again)
+	}
+}
@@ -0,0 +1,62 @@
+package distractors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type HTTPProvider struct {
+	url     string
+	timeout time.Duration
+	client  *http.Client
+}
+
+func NewHTTPProvider(url string, timeout time.Duration) *HTTPProvider {
+	return &HTTPProvider{url: url, timeout: timeout, client: &http.Client{}}
+}
+
+func (p *HTTPProvider) Distractors(ctx context.Context, req Request) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]string{
+		"text":    req.MaskedSentence,
+		"correct": req.CorrectAnswer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("distractor model: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("distractor model: status %d", resp.StatusCode)
+	}
+
+	var data struct {
+		Words []string `json:"words"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("distractor model: decode: %w", err)
+	}
+
+	words := clean(data.Words, req.CorrectAnswer)
+	if len(words) == 0 {
+		return nil, ErrNoDistractors
+	}
+	return words, nil
+}
@@ -0,0 +1,30 @@
+package distractors
+
+import (
+	"context"
+	"math/rand"
+)
+
+// OfflineProvider samples other answers of the same difficulty, so choice
+// tasks still work while the model service is down.
+type OfflineProvider struct {
+	pool  func(difficulty string) []string
+	count int
+}
+
+func NewOfflineProvider(pool func(difficulty string) []string, count int) *OfflineProvider {
+	return &OfflineProvider{pool: pool, count: count}
+}
+
+func (p *OfflineProvider) Distractors(ctx context.Context, req Request) ([]string, error) {
+	candidates := clean(p.pool(req.Difficulty), req.CorrectAnswer)
+	if len(candidates) == 0 {
+		return nil, ErrNoDistractors
+	}
+
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	if len(candidates) > p.count {
+		candidates = candidates[:p.count]
+	}
+	return candidates, nil
+}
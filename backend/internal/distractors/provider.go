@@ -0,0 +1,89 @@
+package distractors
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var ErrNoDistractors = errors.New("no distractors")
+
+type Request struct {
+	MaskedSentence string
+	CorrectAnswer  string
+	Difficulty     string
+}
+
+type Provider interface {
+	Distractors(ctx context.Context, req Request) ([]string, error)
+}
+
+// clean drops blanks, duplicates and the correct answer itself.
+func clean(words []string, correct string) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(correct)): true}
+	out := []string{}
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		key := strings.ToLower(w)
+		if w == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, w)
+	}
+	return out
+}
+
+type fallback struct {
+	primary   Provider
+	secondary Provider
+}
+
+// WithFallback asks primary first and uses secondary when it fails or
+// returns nothing.
+func WithFallback(primary, secondary Provider) Provider {
+	return fallback{primary: primary, secondary: secondary}
+}
+
+func (f fallback) Distractors(ctx context.Context, req Request) ([]string, error) {
+	words, err := f.primary.Distractors(ctx, req)
+	if err == nil && len(words) > 0 {
+		return words, nil
+	}
+	return f.secondary.Distractors(ctx, req)
+}
+
+// FetchAll resolves requests concurrently with at most workers in flight.
+// Results are keyed by masked sentence.
+func FetchAll(ctx context.Context, p Provider, reqs []Request, workers int) map[string][]string {
+	unique := make(map[string]Request)
+	for _, r := range reqs {
+		unique[r.MaskedSentence] = r
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string][]string, len(unique))
+		sem     = make(chan struct{}, workers)
+	)
+	for key, r := range unique {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string, r Request) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			words, err := p.Distractors(ctx, r)
+			if err != nil || len(words) == 0 {
+				return
+			}
+			mu.Lock()
+			results[key] = words
+			mu.Unlock()
+		}(key, r)
+	}
+	wg.Wait()
+	return results
+}
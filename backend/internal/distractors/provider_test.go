@@ -0,0 +1,142 @@
+package distractors
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// fake returns words, or err when set, and counts its lookups.
+type fake struct {
+	words []string
+	err   error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *fake) Distractors(ctx context.Context, req Request) ([]string, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return append([]string(nil), f.words...), nil
+}
+
+func TestClean(t *testing.T) {
+	got := clean([]string{" кітап", "Үй", "", "үй", "қала ", "Кітап"}, "Кітап")
+	want := []string{"Үй", "қала"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clean = %q, want %q", got, want)
+	}
+}
+
+func TestWithFallback(t *testing.T) {
+	errDown := errors.New("model is down")
+	tests := []struct {
+		name          string
+		primary       *fake
+		secondary     *fake
+		want          []string
+		wantErr       error
+		wantSecondary int
+	}{
+		{
+			name:      "primary answers",
+			primary:   &fake{words: []string{"үй"}},
+			secondary: &fake{words: []string{"қала"}},
+			want:      []string{"үй"},
+		},
+		{
+			name:          "primary fails",
+			primary:       &fake{err: errDown},
+			secondary:     &fake{words: []string{"қала"}},
+			want:          []string{"қала"},
+			wantSecondary: 1,
+		},
+		{
+			name:          "primary has nothing",
+			primary:       &fake{},
+			secondary:     &fake{words: []string{"қала"}},
+			want:          []string{"қала"},
+			wantSecondary: 1,
+		},
+		{
+			name:          "both fail",
+			primary:       &fake{err: errDown},
+			secondary:     &fake{err: ErrNoDistractors},
+			wantErr:       ErrNoDistractors,
+			wantSecondary: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WithFallback(tt.primary, tt.secondary).Distractors(context.Background(), Request{MaskedSentence: "<mask> бар"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("words = %q, want %q", got, tt.want)
+			}
+			if tt.secondary.calls != tt.wantSecondary {
+				t.Errorf("secondary calls = %d, want %d", tt.secondary.calls, tt.wantSecondary)
+			}
+		})
+	}
+}
+
+func TestOfflineProvider(t *testing.T) {
+	pools := map[string][]string{
+		"A1": {"кітап", "үй", "қала", "су", "нан", "Кітап"},
+		"B2": {"кітап"},
+	}
+	p := NewOfflineProvider(func(difficulty string) []string { return pools[difficulty] }, 3)
+
+	got, err := p.Distractors(context.Background(), Request{CorrectAnswer: "кітап", Difficulty: "A1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Errorf("got %d distractors, want 3", len(got))
+	}
+	for _, w := range got {
+		if w == "кітап" || w == "Кітап" {
+			t.Errorf("distractors %q include the correct answer", got)
+		}
+	}
+
+	if _, err := p.Distractors(context.Background(), Request{CorrectAnswer: "кітап", Difficulty: "B2"}); !errors.Is(err, ErrNoDistractors) {
+		t.Errorf("err = %v, want %v", err, ErrNoDistractors)
+	}
+}
+
+func TestFetchAll(t *testing.T) {
+	p := &fake{words: []string{"үй"}}
+	reqs := []Request{
+		{MaskedSentence: "<mask> бар"},
+		{MaskedSentence: "<mask> жоқ"},
+		{MaskedSentence: "<mask> бар"},
+	}
+
+	got := FetchAll(context.Background(), p, reqs, 2)
+	keys := make([]string, 0, len(got))
+	for k := range got {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if want := []string{"<mask> бар", "<mask> жоқ"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %q, want %q", keys, want)
+	}
+	if p.calls != 2 {
+		t.Errorf("calls = %d, duplicate sentences should be fetched once", p.calls)
+	}
+
+	if got := FetchAll(context.Background(), &fake{err: ErrNoDistractors}, reqs, 2); len(got) != 0 {
+		t.Errorf("failed lookups returned %q", got)
+	}
+}
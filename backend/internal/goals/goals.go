@@ -0,0 +1,192 @@
+package goals
+
+import (
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/wallet"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const dateLayout = "2006-01-02"
+
+// A daily goal counts either words learned or level XP earned in a local day.
+const (
+	MetricWords = "words"
+	MetricXp    = "xp"
+)
+
+// Targets outside these bounds are rejected.
+var maxTarget = map[string]int{
+	MetricWords: 100,
+	MetricXp:    1000,
+}
+
+const DefaultTarget = 5
+
+var ErrInvalidGoal = errors.New("goals: invalid metric or target")
+
+// FromStudyTime is the words goal matching the study time picked during
+// onboarding.
+func FromStudyTime(studyTime string) int {
+	switch studyTime {
+	case "one":
+		return 3
+	case "three":
+		return 8
+	case "more":
+		return 12
+	}
+	return DefaultTarget
+}
+
+func Valid(metric string, target int) bool {
+	limit, ok := maxTarget[metric]
+	return ok && target > 0 && target <= limit
+}
+
+// Set makes metric and target the user's goal from the local day onward and
+// records it in the goal history. Setting the goal twice on one day keeps
+// the last one. The caller saves the user.
+func Set(tx *gorm.DB, user *models.User, metric string, target int, day string) error {
+	if !Valid(metric, target) {
+		return ErrInvalidGoal
+	}
+	user.DailyGoalMetric = metric
+	user.DailyGoal = target
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "effective_from"}},
+		DoUpdates: clause.AssignmentColumns([]string{"metric", "target", "created_at"}),
+	}).Create(&models.DailyGoal{UserID: user.ID, Metric: metric, Target: target, EffectiveFrom: day}).Error
+}
+
+// TreeXpReward is the tree XP paid for meeting a goal. An XP goal is worth
+// about as much as a words goal of a tenth of its size.
+func TreeXpReward(metric string, target int) int {
+	if metric == MetricXp {
+		return max(target/10, 1)
+	}
+	return target
+}
+
+// Today returns the user's progress toward today's goal, including the
+// changes still pending in ledger.
+func Today(db *gorm.DB, user *models.User, ledger *wallet.Ledger) (int, error) {
+	if user.DailyGoalMetric != MetricXp {
+		return user.TodayLearnedWords, nil
+	}
+	today := user.LocalNow().Format(dateLayout)
+	values, err := progress(db, user, MetricXp, today, today)
+	if err != nil {
+		return 0, err
+	}
+	return values[today] + ledger.Pending(wallet.Xp), nil
+}
+
+type Day struct {
+	Date   string `json:"date"`
+	Metric string `json:"metric,omitempty"`
+	Target int    `json:"target,omitempty"`
+	Value  int    `json:"value"`
+	// Status is met, missed, pending for today when the goal is not met
+	// yet, or none for days before the user had a goal.
+	Status string `json:"status"`
+}
+
+const (
+	StatusMet     = "met"
+	StatusMissed  = "missed"
+	StatusPending = "pending"
+	StatusNone    = "none"
+)
+
+// Calendar returns the last days local days up to and including today,
+// oldest first, each judged against the goal in force on that day.
+func Calendar(db *gorm.DB, user *models.User, days int, now time.Time) ([]Day, error) {
+	now = now.In(user.Location())
+	today := now.Format(dateLayout)
+	from := now.AddDate(0, 0, -(days - 1)).Format(dateLayout)
+
+	var history []models.DailyGoal
+	if err := db.Where("user_id = ?", user.ID).Order("effective_from").Find(&history).Error; err != nil {
+		return nil, err
+	}
+	values := map[string]map[string]int{}
+	for _, metric := range []string{MetricWords, MetricXp} {
+		v, err := progress(db, user, metric, from, today)
+		if err != nil {
+			return nil, err
+		}
+		values[metric] = v
+	}
+	return calendarDays(history, values, days, now), nil
+}
+
+// calendarDays judges the last days local days up to now against history,
+// given each metric's value per local date.
+func calendarDays(history []models.DailyGoal, values map[string]map[string]int, days int, now time.Time) []Day {
+	today := now.Format(dateLayout)
+	out := make([]Day, 0, days)
+	for i := days - 1; i >= 0; i-- {
+		date := now.AddDate(0, 0, -i).Format(dateLayout)
+		d := Day{Date: date, Status: StatusNone}
+		if g, ok := inForce(history, date); ok {
+			d.Metric, d.Target = g.Metric, g.Target
+			d.Value = values[g.Metric][date]
+			switch {
+			case d.Value >= d.Target:
+				d.Status = StatusMet
+			case date == today:
+				d.Status = StatusPending
+			default:
+				d.Status = StatusMissed
+			}
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// inForce returns the goal of the latest history entry that started on or
+// before date. history must be sorted by EffectiveFrom.
+func inForce(history []models.DailyGoal, date string) (models.DailyGoal, bool) {
+	i := sort.Search(len(history), func(i int) bool { return history[i].EffectiveFrom > date })
+	if i == 0 {
+		return models.DailyGoal{}, false
+	}
+	return history[i-1], true
+}
+
+// progress sums the metric per local day from the ledger: a word counts
+// when its word_learned coin was paid, XP is every XP gain apart from
+// balances carried over when the account was opened.
+func progress(db *gorm.DB, user *models.User, metric, from, to string) (map[string]int, error) {
+	q := db.Model(&models.LedgerEntry{}).
+		Where("user_id = ?", user.ID).
+		Where("to_char(created_at AT TIME ZONE ?, 'YYYY-MM-DD') BETWEEN ? AND ?", user.Location().String(), from, to)
+	day := gorm.Expr("to_char(created_at AT TIME ZONE ?, 'YYYY-MM-DD')", user.Location().String())
+
+	if metric == MetricXp {
+		q = q.Select("? AS day, SUM(amount) AS value", day).
+			Where("currency = ? AND amount > 0 AND reason NOT IN ?", wallet.Xp, []string{wallet.ReasonOpening, wallet.ReasonSignup})
+	} else {
+		q = q.Select("? AS day, COUNT(*) AS value", day).
+			Where("reason = ?", wallet.ReasonWordLearned)
+	}
+
+	var rows []struct {
+		Day   string
+		Value int
+	}
+	if err := q.Group("day").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]int, len(rows))
+	for _, r := range rows {
+		out[r.Day] = r.Value
+	}
+	return out, nil
+}
@@ -0,0 +1,66 @@
+package goals
+
+import (
+	"TalUpBackend/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCalendarDays(t *testing.T) {
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, almaty)
+	history := []models.DailyGoal{
+		{Metric: MetricWords, Target: 5, EffectiveFrom: "2024-03-06"},
+		{Metric: MetricXp, Target: 50, EffectiveFrom: "2024-03-08"},
+		{Metric: MetricWords, Target: 3, EffectiveFrom: "2024-03-10"},
+	}
+	values := map[string]map[string]int{
+		MetricWords: {"2024-03-05": 9, "2024-03-06": 5, "2024-03-07": 4, "2024-03-08": 6, "2024-03-10": 2},
+		MetricXp:    {"2024-03-08": 40, "2024-03-09": 75, "2024-03-10": 90},
+	}
+
+	got := calendarDays(history, values, 6, now)
+	want := []Day{
+		{Date: "2024-03-05", Status: StatusNone},
+		{Date: "2024-03-06", Metric: MetricWords, Target: 5, Value: 5, Status: StatusMet},
+		{Date: "2024-03-07", Metric: MetricWords, Target: 5, Value: 4, Status: StatusMissed},
+		{Date: "2024-03-08", Metric: MetricXp, Target: 50, Value: 40, Status: StatusMissed},
+		{Date: "2024-03-09", Metric: MetricXp, Target: 50, Value: 75, Status: StatusMet},
+		{Date: "2024-03-10", Metric: MetricWords, Target: 3, Value: 2, Status: StatusPending},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("calendar =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCalendarDaysTodayMet(t *testing.T) {
+	now := time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC)
+	history := []models.DailyGoal{{Metric: MetricWords, Target: 3, EffectiveFrom: "2024-01-01"}}
+	values := map[string]map[string]int{MetricWords: {"2024-03-10": 3}}
+
+	got := calendarDays(history, values, 2, now)
+	if len(got) != 2 || got[0].Status != StatusMissed || got[1].Status != StatusMet {
+		t.Errorf("calendar = %+v, want yesterday missed and today met", got)
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		metric string
+		target int
+		want   bool
+	}{
+		{MetricWords, 1, true},
+		{MetricWords, 100, true},
+		{MetricWords, 101, false},
+		{MetricXp, 1000, true},
+		{MetricXp, 0, false},
+		{"minutes", 10, false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.metric, tt.target); got != tt.want {
+			t.Errorf("Valid(%q, %d) = %v, want %v", tt.metric, tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/achievements"
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/middleware"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAchievements lists every achievement with the caller's progress and,
+// for unlocked ones, when they were unlocked.
+func GetAchievements(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	list, err := achievements.List(db.DB, &user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить достижения"})
+		return
+	}
+
+	unlocked := 0
+	for _, p := range list {
+		if p.Unlocked {
+			unlocked++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"achievements": list, "unlocked": unlocked, "total": len(list)})
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/content"
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/models"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type wordInput struct {
+	Word        string `json:"word" binding:"required"`
+	Translation string `json:"translation"`
+}
+
+type sentenceInput struct {
+	WordID            uint   `json:"word_id" binding:"required"`
+	Text              string `json:"text"`
+	MaskedSentence    string `json:"masked_sentence" binding:"required"`
+	CorrectAnswer     string `json:"correct_answer" binding:"required"`
+	Translation       string `json:"translation"`
+	TranslationTarget string `json:"translation_target"`
+	Difficulty        string `json:"difficulty" binding:"required"`
+}
+
+func (in sentenceInput) toModel() models.Sentence {
+	return models.Sentence{
+		WordID:            in.WordID,
+		Text:              strings.TrimSpace(in.Text),
+		MaskedSentence:    strings.TrimSpace(in.MaskedSentence),
+		CorrectAnswer:     strings.TrimSpace(in.CorrectAnswer),
+		Translation:       strings.TrimSpace(in.Translation),
+		TranslationTarget: strings.TrimSpace(in.TranslationTarget),
+		Difficulty:        strings.ToUpper(strings.TrimSpace(in.Difficulty)),
+	}
+}
+
+func idParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный идентификатор"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// reloadContent refreshes the task set served to learners after an edit.
+func reloadContent(c *gin.Context) bool {
+	if err := contentRepo.Reload(); err != nil {
+		log.Println("Ошибка перезагрузки контента:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Изменения сохранены, но контент не перезагружен"})
+		return false
+	}
+	distractorCache.Purge()
+	return true
+}
+
+func ListWords(c *gin.Context) {
+	var words []models.Word
+	if err := db.DB.Order("id").Find(&words).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить слова"})
+		return
+	}
+	c.JSON(http.StatusOK, words)
+}
+
+func CreateWord(c *gin.Context) {
+	var input wordInput
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Word) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные слова"})
+		return
+	}
+
+	word := models.Word{Word: strings.TrimSpace(input.Word), Translation: strings.TrimSpace(input.Translation)}
+	if err := db.DB.Create(&word).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сохранить слово"})
+		return
+	}
+	if !reloadContent(c) {
+		return
+	}
+	c.JSON(http.StatusCreated, word)
+}
+
+func UpdateWord(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	var input wordInput
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Word) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные слова"})
+		return
+	}
+
+	var word models.Word
+	if err := db.DB.First(&word, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Слово не найдено"})
+		return
+	}
+	word.Word = strings.TrimSpace(input.Word)
+	word.Translation = strings.TrimSpace(input.Translation)
+	if err := db.DB.Save(&word).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сохранить слово"})
+		return
+	}
+	if !reloadContent(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, word)
+}
+
+var errWordInUse = errors.New("word in use")
+
+// DeleteWord removes a word nobody has progress on or an unanswered task
+// for. The word row stays locked from the check to the delete, so no
+// progress row can reference it in between.
+func DeleteWord(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var word models.Word
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&word, id).Error; err != nil {
+			return err
+		}
+		var progress, pending int64
+		if err := tx.Model(&models.UserWord{}).Where("word_id = ?", id).Count(&progress).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.IssuedTask{}).Where("word_id = ? AND consumed_at IS NULL", id).Count(&pending).Error; err != nil {
+			return err
+		}
+		if progress > 0 || pending > 0 {
+			return errWordInUse
+		}
+		return tx.Delete(&word).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Слово не найдено"})
+		return
+	case errors.Is(err, errWordInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "Слово уже изучается пользователями"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось удалить слово"})
+		return
+	}
+	if !reloadContent(c) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Слово удалено"})
+}
+
+func ListWordSentences(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var sentences []models.Sentence
+	if err := db.DB.Where("word_id = ?", id).Order("id").Find(&sentences).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить предложения"})
+		return
+	}
+	c.JSON(http.StatusOK, sentences)
+}
+
+func validateSentenceInput(c *gin.Context, s models.Sentence) bool {
+	err := errors.Join(content.ValidateSentence(s), content.CheckStrict(s))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	var word models.Word
+	if err := db.DB.First(&word, s.WordID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("word_id %d does not exist", s.WordID)})
+		return false
+	}
+	return true
+}
+
+func CreateSentence(c *gin.Context) {
+	var input sentenceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные предложения"})
+		return
+	}
+
+	sentence := input.toModel()
+	if !validateSentenceInput(c, sentence) {
+		return
+	}
+	if err := db.DB.Create(&sentence).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сохранить предложение"})
+		return
+	}
+	if !reloadContent(c) {
+		return
+	}
+	c.JSON(http.StatusCreated, sentence)
+}
+
+func UpdateSentence(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	var input sentenceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные предложения"})
+		return
+	}
+
+	var existing models.Sentence
+	if err := db.DB.First(&existing, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Предложение не найдено"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить предложение"})
+		}
+		return
+	}
+
+	sentence := input.toModel()
+	sentence.ID = existing.ID
+	if !validateSentenceInput(c, sentence) {
+		return
+	}
+	if err := db.DB.Save(&sentence).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сохранить предложение"})
+		return
+	}
+	if !reloadContent(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, sentence)
+}
+
+func DeleteSentence(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	res := db.DB.Delete(&models.Sentence{}, id)
+	if res.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось удалить предложение"})
+		return
+	}
+	if res.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Предложение не найдено"})
+		return
+	}
+	if !reloadContent(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Предложение удалено"})
+}
+
+func ReloadContent(c *gin.Context) {
+	if !reloadContent(c) {
+		return
+	}
+	words, sentences := contentRepo.Counts()
+	c.JSON(http.StatusOK, gin.H{"words": words, "sentences": sentences})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/middleware"
+	"TalUpBackend/internal/models"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func UpdateUserRole(c *gin.Context) {
+	current, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || !models.IsValidRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная роль", "roles": models.Roles})
+		return
+	}
+	if id == current.ID && input.Role != models.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя снять с себя роль администратора"})
+		return
+	}
+
+	res := db.DB.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{"role": input.Role, "version": gorm.Expr("version + 1")})
+	if res.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить роль"})
+		return
+	}
+	if res.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+		return
+	}
+
+	log.Printf("Роль изменена. ID: %d, роль: %s, изменил: %d", id, input.Role, current.ID)
+	c.JSON(http.StatusOK, gin.H{"id": id, "role": input.Role})
+}
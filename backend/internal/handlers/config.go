@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/asr"
+	"TalUpBackend/internal/auth"
+	"TalUpBackend/internal/config"
+	"TalUpBackend/internal/content"
+	"TalUpBackend/internal/distractors"
+	"TalUpBackend/internal/lives"
+)
+
+const distractorsPerTask = 3
+
+var (
+	tokens           *auth.Manager
+	contentRepo      *content.Repository
+	distractorSource distractors.Provider
+	distractorCache  *distractors.CachedProvider
+	speechRecognizer asr.Client
+	pronunciationCfg config.PronunciationConfig
+	lifePool         *lives.Pool
+)
+
+func Configure(cfg config.Config, tokenManager *auth.Manager, repo *content.Repository) {
+	tokens = tokenManager
+	contentRepo = repo
+
+	distractorCache = distractors.NewCachedProvider(
+		distractors.NewHTTPProvider(cfg.Models.DistractorURL, cfg.Models.DistractorTimeout),
+		cfg.Models.DistractorCacheSize,
+	)
+	distractorSource = distractors.WithFallback(
+		distractorCache,
+		distractors.NewOfflineProvider(answersByDifficulty, distractorsPerTask),
+	)
+
+	pronunciationCfg = cfg.Pronunciation
+	lifePool = lives.NewPool(cfg.Lives)
+
+	if cfg.Models.TranscribeBackend == "stub" {
+		speechRecognizer = asr.Stub{Text: cfg.Models.TranscribeStubText}
+	} else {
+		speechRecognizer = asr.NewHTTPClient(cfg.Models.TranscribeURL, cfg.Models.TranscribeTimeout, cfg.Models.TranscribeRetries)
+	}
+}
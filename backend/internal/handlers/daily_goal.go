@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/goals"
+	"TalUpBackend/internal/middleware"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/rewards"
+	"TalUpBackend/internal/wallet"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// goalCalendarDays is how many local days GetGoals reports, today included.
+const goalCalendarDays = 30
+
+// grantDailyGoal pays the daily goal reward once the day's goal is reached.
+// Every request that may complete the goal calls it; the reward is paid
+// once per local day whichever comes first.
+func grantDailyGoal(tx *gorm.DB, user *models.User, ledger *wallet.Ledger) error {
+	value, err := goals.Today(tx, user, ledger)
+	if err != nil || value < user.DailyGoal {
+		return err
+	}
+	reward := rewards.DailyGoal(goals.TreeXpReward(user.DailyGoalMetric, user.DailyGoal))
+	_, err = rewards.Grant(tx, user, ledger, reward, user.LocalNow().Format("2006-01-02"))
+	return err
+}
+
+// GetGoals returns the current goal, today's progress toward it and a
+// calendar of the last goalCalendarDays days.
+func GetGoals(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	calendar, err := goals.Calendar(db.DB, &user, goalCalendarDays, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить цели"})
+		return
+	}
+	met := 0
+	for _, d := range calendar {
+		if d.Status == goals.StatusMet {
+			met++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"metric":   user.DailyGoalMetric,
+		"target":   user.DailyGoal,
+		"today":    calendar[len(calendar)-1],
+		"calendar": calendar,
+		"metDays":  met,
+	})
+}
+
+// UpdateGoal sets a new daily goal. It applies from today on; past days
+// keep the goal they had.
+func UpdateGoal(c *gin.Context) {
+	current, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Metric string `json:"metric"`
+		Target int    `json:"target"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные цели"})
+		return
+	}
+	if body.Metric == "" {
+		body.Metric = goals.MetricWords
+	}
+
+	user, err := updateUser(current.ID, func(tx *gorm.DB, user *models.User, ledger *wallet.Ledger) error {
+		if err := goals.Set(tx, user, body.Metric, body.Target, user.LocalNow().Format("2006-01-02")); err != nil {
+			return err
+		}
+		// A lower goal may already be met today.
+		return grantDailyGoal(tx, user, ledger)
+	})
+	if errors.Is(err, goals.ErrInvalidGoal) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная цель", "metrics": []string{goals.MetricWords, goals.MetricXp}})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сохранить цель"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"metric": user.DailyGoalMetric, "target": user.DailyGoal})
+}
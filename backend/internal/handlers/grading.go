@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/models"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errUnknownTask  = errors.New("unknown task")
+	errTaskConsumed = errors.New("task already submitted")
+	errReadingTask  = errors.New("reading tasks are graded by asr-submit")
+)
+
+func normalizeAnswer(s string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(s), ".,!?:;"))
+}
+
+func shuffleTokens(sentence string) []string {
+	words := strings.FieldsFunc(sentence, func(r rune) bool {
+		return r == ' ' || r == '.' || r == ',' || r == '?' || r == '!'
+	})
+	for i, w := range words {
+		words[i] = normalizeAnswer(w)
+	}
+	return words
+}
+
+func issueTask(userID uint, sessionID *uint, task Task, expected string) error {
+	return db.DB.Create(&models.IssuedTask{
+		ID:             task.ID,
+		UserID:         userID,
+		SessionID:      sessionID,
+		WordID:         task.WordID,
+		Type:           task.Type,
+		Difficulty:     task.Difficulty,
+		ExpectedAnswer: expected,
+	}).Error
+}
+
+func gradeAnswer(issued models.IssuedTask, input SubmitInput) (bool, error) {
+	switch issued.Type {
+	case "standard", "word_translation":
+		return normalizeAnswer(input.Answer) == normalizeAnswer(issued.ExpectedAnswer), nil
+	case "sentence_shuffle":
+		expected := strings.Fields(issued.ExpectedAnswer)
+		if len(input.Answers) != len(expected) {
+			return false, nil
+		}
+		for i, w := range input.Answers {
+			if normalizeAnswer(w) != expected[i] {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "asr_reading":
+		return false, errReadingTask
+	}
+	return false, errUnknownTask
+}
+
+// consumeTask marks the task as submitted in a single conditional update, so
+// two concurrent submits of the same task cannot both be graded.
+func consumeTask(userID uint, taskID string) (models.IssuedTask, error) {
+	var issued models.IssuedTask
+	if err := db.DB.Where("id = ? AND user_id = ?", taskID, userID).First(&issued).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return issued, errUnknownTask
+		}
+		return issued, err
+	}
+	if issued.ConsumedAt != nil {
+		return issued, errTaskConsumed
+	}
+	if issued.Type == "asr_reading" {
+		return issued, errReadingTask
+	}
+
+	now := time.Now()
+	res := db.DB.Model(&models.IssuedTask{}).
+		Where("id = ? AND consumed_at IS NULL", issued.ID).
+		Update("consumed_at", now)
+	if res.Error != nil {
+		return issued, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return issued, errTaskConsumed
+	}
+	issued.ConsumedAt = &now
+	return issued, nil
+}
+
+// consumeReading stores the recognizer verdict and score for a reading task
+// and marks it submitted, with the same single-use guarantee as consumeTask.
+func consumeReading(issued models.IssuedTask, passed bool, score float64) error {
+	res := db.DB.Model(&models.IssuedTask{}).
+		Where("id = ? AND consumed_at IS NULL", issued.ID).
+		Updates(map[string]interface{}{"consumed_at": time.Now(), "asr_passed": passed, "asr_score": score})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errTaskConsumed
+	}
+	return nil
+}
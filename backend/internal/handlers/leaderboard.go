@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/leaderboard"
+	"TalUpBackend/internal/middleware"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/social"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLeaderboardLimit = 50
+	maxLeaderboardLimit     = 100
+	// leaderboardRadius is how many neighbors are shown on each side of the
+	// caller's own entry.
+	leaderboardRadius = 2
+	// hiddenUserName replaces the name of users whose profile is hidden
+	// from the caller.
+	hiddenUserName = "Скрытый профиль"
+)
+
+// GetLeaderboard ranks users by the XP earned in a period. By default the
+// weekly board of the caller's league is returned; scope=global ranks all
+// users and scope=friends ranks the caller and their friends. The caller's
+// own entry and neighbors are always returned in "around", even when they
+// are outside the page. Users whose profile the caller may not view are
+// listed without their name and avatar.
+func GetLeaderboard(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	period := c.DefaultQuery("period", leaderboard.PeriodWeek)
+	scope := c.DefaultQuery("scope", "league")
+	if scope != "league" && scope != "global" && scope != "friends" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный scope"})
+		return
+	}
+	limit := defaultLeaderboardLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный limit"})
+			return
+		}
+		limit = min(n, maxLeaderboardLimit)
+	}
+
+	q := leaderboard.Query{Period: period, Now: time.Now()}
+	switch scope {
+	case "league":
+		q.League = &user.League
+	case "friends":
+		q.FriendsOf = user.ID
+	}
+
+	entries, next, err := leaderboard.Page(db.DB, q, c.Query("cursor"), limit)
+	if err != nil {
+		leaderboardError(c, err)
+		return
+	}
+	around, err := leaderboard.Around(db.DB, q, user.ID, leaderboardRadius)
+	if err != nil {
+		leaderboardError(c, err)
+		return
+	}
+	friends, err := social.FriendIDs(db.DB, user.ID)
+	if err != nil {
+		leaderboardError(c, err)
+		return
+	}
+	var me gin.H
+	for _, e := range around {
+		if e.UserID == user.ID {
+			me = leaderboardEntry(c, e, user.ID, friends)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"period":     period,
+		"scope":      scope,
+		"league":     user.League,
+		"leagueName": leaderboard.LeagueName(user.League),
+		"entries":    leaderboardEntries(c, entries, user.ID, friends),
+		"nextCursor": next,
+		"me":         me,
+		"around":     leaderboardEntries(c, around, user.ID, friends),
+	})
+}
+
+func leaderboardError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, leaderboard.ErrUnknownPeriod):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный период", "periods": []string{
+			leaderboard.PeriodWeek, leaderboard.PeriodMonth, leaderboard.PeriodAll,
+		}})
+	case errors.Is(err, leaderboard.ErrBadCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный cursor"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить таблицу лидеров"})
+	}
+}
+
+// GetLeagueHistory returns the caller's results of past league weeks,
+// newest first.
+func GetLeagueHistory(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var results []models.LeagueResult
+	if err := db.DB.Where("user_id = ?", user.ID).
+		Order("week_start DESC").Limit(defaultLeaderboardLimit).
+		Find(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить историю лиг"})
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+func leaderboardEntries(c *gin.Context, entries []leaderboard.Entry, currentUserID uint, friends map[uint]bool) []gin.H {
+	out := make([]gin.H, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, leaderboardEntry(c, e, currentUserID, friends))
+	}
+	return out
+}
+
+func leaderboardEntry(c *gin.Context, e leaderboard.Entry, currentUserID uint, friends map[uint]bool) gin.H {
+	hidden := !social.Visible(currentUserID, e.UserID, e.Visibility, friends)
+	name, avatar := e.Name, avatarURL(c, e.Avatar)
+	if hidden {
+		name, avatar = hiddenUserName, ""
+	}
+	return gin.H{
+		"id":        e.UserID,
+		"name":      name,
+		"avatar":    avatar,
+		"hidden":    hidden,
+		"league":    e.League,
+		"score":     e.Score,
+		"position":  e.Position,
+		"isCurrent": e.UserID == currentUserID,
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/achievements"
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/middleware"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/wallet"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Completing every task of a session earns sessionBonusXp; doing it without
+// a mistake adds sessionPerfectCoins.
+const (
+	sessionBonusXp      = 5
+	sessionPerfectCoins = 2
+)
+
+var errSessionFinished = errors.New("session already finished")
+
+func StartLearningSession(c *gin.Context) {
+	current, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := refreshLives(current.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить жизни"})
+		return
+	}
+	if user.Lives+user.BonusLives <= 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно жизней"})
+		return
+	}
+
+	// Starting a new lesson abandons an unfinished one; it can no longer
+	// earn a completion bonus.
+	db.DB.Model(&models.LearningSession{}).
+		Where("user_id = ? AND status = ?", user.ID, models.SessionActive).
+		Update("status", models.SessionAbandoned)
+
+	session := models.LearningSession{UserID: user.ID, Status: models.SessionActive, StartedAt: time.Now()}
+	if err := db.DB.Create(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось начать урок"})
+		return
+	}
+
+	tasks, err := selectTasks(c.Request.Context(), user, &session.ID)
+	if err != nil {
+		db.DB.Delete(&session)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось начать урок"})
+		return
+	}
+	if tasks == nil {
+		db.DB.Delete(&session)
+		c.JSON(http.StatusNotFound, gin.H{"error": "no suitable tasks found"})
+		return
+	}
+	session.TaskCount = len(tasks)
+	db.DB.Model(&session).Update("task_count", session.TaskCount)
+	c.JSON(http.StatusOK, gin.H{"session": session, "tasks": tasks})
+}
+
+func GetLearningSession(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var session models.LearningSession
+	if err := db.DB.Where("id = ? AND user_id = ?", id, user.ID).First(&session).Error; err != nil {
+		sessionError(c, err)
+		return
+	}
+
+	if session.Status == models.SessionActive {
+		if err := summarizeSession(db.DB, &session, time.Now()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить урок"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// FinishLearningSession closes the session under a row lock, so the summary
+// is computed and the completion bonus awarded exactly once. Finishing an
+// already finished session returns the stored summary.
+func FinishLearningSession(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var session models.LearningSession
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", id, user.ID).First(&session).Error; err != nil {
+			return err
+		}
+		if session.Status != models.SessionActive {
+			return errSessionFinished
+		}
+
+		now := time.Now()
+		if err := summarizeSession(tx, &session, now); err != nil {
+			return err
+		}
+		session.Status = models.SessionFinished
+		session.FinishedAt = &now
+
+		if session.TaskCount > 0 && session.Answered == session.TaskCount {
+			session.BonusXp = sessionBonusXp
+			if session.Correct == session.Answered {
+				session.BonusCoins = sessionPerfectCoins
+			}
+		}
+		// The session is saved first so that achievements counting finished
+		// sessions see it.
+		if err := tx.Save(&session).Error; err != nil {
+			return err
+		}
+		src := wallet.Source{Type: "session", ID: strconv.FormatUint(uint64(session.ID), 10)}
+		_, err := updateUserTx(tx, user.ID, func(tx *gorm.DB, user *models.User, ledger *wallet.Ledger) error {
+			ledger.Add(wallet.Xp, session.BonusXp, wallet.ReasonSessionBonus, src)
+			ledger.Add(wallet.Coins, session.BonusCoins, wallet.ReasonSessionBonus, src)
+			applyLevelUp(user, ledger, src)
+			_, err := achievements.Evaluate(tx, user, ledger, achievements.EventSessionFinished)
+			return err
+		})
+		return err
+	})
+	if errors.Is(err, errSessionFinished) {
+		c.JSON(http.StatusOK, session)
+		return
+	}
+	if err != nil {
+		sessionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+func sessionError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Урок не найден"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить урок"})
+}
+
+// summarizeSession fills the summary from the session's graded tasks and
+// the ledger entries they produced.
+func summarizeSession(tx *gorm.DB, session *models.LearningSession, now time.Time) error {
+	var counts struct {
+		Answered int
+		Correct  int
+	}
+	if err := tx.Model(&models.IssuedTask{}).
+		Select("COUNT(*) FILTER (WHERE consumed_at IS NOT NULL) AS answered, COUNT(*) FILTER (WHERE correct) AS correct").
+		Where("session_id = ?", session.ID).
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+
+	var earned struct {
+		Xp    int
+		Words int
+	}
+	tasks := tx.Model(&models.IssuedTask{}).Select("id").Where("session_id = ?", session.ID)
+	if err := tx.Model(&models.LedgerEntry{}).
+		Select("COALESCE(SUM(amount) FILTER (WHERE currency = ? AND reason = ?), 0) AS xp, COUNT(*) FILTER (WHERE reason = ?) AS words",
+			wallet.Xp, wallet.ReasonTaskReward, wallet.ReasonWordLearned).
+		Where("source_type = ? AND source_id IN (?)", "task", tasks).
+		Scan(&earned).Error; err != nil {
+		return err
+	}
+
+	session.Answered = counts.Answered
+	session.Correct = counts.Correct
+	session.Accuracy = 0
+	if counts.Answered > 0 {
+		session.Accuracy = float64(counts.Correct) / float64(counts.Answered)
+	}
+	session.XpEarned = earned.Xp
+	session.WordsLearned = earned.Words
+	session.DurationSeconds = int(now.Sub(session.StartedAt).Seconds())
+	return nil
+}
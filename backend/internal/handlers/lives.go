@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/middleware"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/wallet"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// refreshLives brings the user's lives up to date before they are read.
+func refreshLives(userID uint) (models.User, error) {
+	return updateUser(userID, func(tx *gorm.DB, user *models.User, ledger *wallet.Ledger) error {
+		lifePool.Refill(user, ledger, time.Now())
+		return nil
+	})
+}
+
+// secondsUntil rounds up, so a client that waits that long sees the life.
+func secondsUntil(t, now time.Time) int {
+	if t.IsZero() || !t.After(now) {
+		return 0
+	}
+	return int(math.Ceil(t.Sub(now).Seconds()))
+}
+
+func GetLives(c *gin.Context) {
+	current, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := refreshLives(current.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить жизни"})
+		return
+	}
+
+	now := time.Now()
+	next, full := lifePool.NextRefill(user)
+	response := gin.H{
+		"lives":             user.Lives,
+		"bonusLives":        user.BonusLives,
+		"totalLives":        user.Lives + user.BonusLives,
+		"maxLives":          lifePool.Max(),
+		"nextLifeAt":        nil,
+		"fullAt":            nil,
+		"nextLifeInSeconds": secondsUntil(next, now),
+		"serverTime":        now.Format(time.RFC3339),
+	}
+	if !next.IsZero() {
+		response["nextLifeAt"] = next.Format(time.RFC3339)
+		response["fullAt"] = full.Format(time.RFC3339)
+	}
+	c.JSON(http.StatusOK, response)
+}
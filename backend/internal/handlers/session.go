@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/auth"
+	"TalUpBackend/internal/middleware"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RefreshToken(c *gin.Context) {
+	var input struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные в запросе"})
+		return
+	}
+
+	pair, userID, err := tokens.Refresh(input.RefreshToken)
+	switch err {
+	case nil:
+	case auth.ErrTokenReused:
+		log.Printf("Повторное использование refresh-токена, сессия отозвана. ID: %d", userID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Сессия отозвана. Войдите заново"})
+		return
+	case auth.ErrInvalidToken, auth.ErrTokenExpired:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Сессия истекла. Войдите заново"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка авторизации. Попробуйте позже"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"expires_in":    pair.ExpiresIn,
+	})
+}
+
+func Logout(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+	sessionID := middleware.CurrentSessionID(c)
+
+	if err := tokens.RevokeSession(user.ID, sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выйти. Попробуйте позже"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Вы вышли из аккаунта"})
+}
+
+func LogoutAll(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := tokens.RevokeAll(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выйти. Попробуйте позже"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Вы вышли со всех устройств"})
+}
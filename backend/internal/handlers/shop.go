@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/middleware"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/shop"
+	"TalUpBackend/internal/streak"
+	"TalUpBackend/internal/wallet"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func ListShopItems(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	items, err := shop.Items(db.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить магазин"})
+		return
+	}
+	var inventory []models.UserItem
+	if err := db.DB.Where("user_id = ? AND quantity > 0", user.ID).Find(&inventory).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить инвентарь"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"inventory": inventory,
+		"coins":     user.Coins,
+	})
+}
+
+func PurchaseItem(c *gin.Context) {
+	var input struct {
+		ItemID   string `json:"item_id" binding:"required"`
+		Quantity int    `json:"quantity"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные покупки"})
+		return
+	}
+	if input.Quantity == 0 {
+		input.Quantity = 1
+	}
+	if input.Quantity < 0 || input.Quantity > shop.MaxQuantity {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректное количество"})
+		return
+	}
+
+	res, ok := purchase(c, input.ItemID, input.Quantity)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Покупка совершена!",
+		"purchase":     res.Purchase,
+		"owned":        res.Owned,
+		"coins":        res.User.Coins,
+		"lives":        res.User.Lives,
+		"streak":       res.User.StreakCount,
+		"repairedDays": res.RepairedDays,
+		"achievements": res.Achievements,
+	})
+}
+
+// BuyLife is kept for older clients; it is a purchase of one "life" item.
+func BuyLife(c *gin.Context) {
+	res, ok := purchase(c, models.ItemLife, 1)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Жизнь куплена!",
+		"lives":   res.User.Lives,
+		"coins":   res.User.Coins,
+	})
+}
+
+// purchase rolls the user into today first, so a streak broken by this
+// visit can be repaired right away, then runs the checkout and writes the
+// error response itself.
+func purchase(c *gin.Context, itemID string, qty int) (shop.Result, bool) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return shop.Result{}, false
+	}
+	_, err := updateUser(user.ID, func(tx *gorm.DB, user *models.User, ledger *wallet.Ledger) error {
+		_, err := rollover(tx, user, ledger)
+		return err
+	})
+	var res shop.Result
+	if err == nil {
+		res, err = shop.Purchase(db.DB, lifePool, user.ID, itemID, qty)
+	}
+	if err == nil {
+		return res, true
+	}
+
+	status, msg := http.StatusBadRequest, ""
+	switch {
+	case errors.Is(err, shop.ErrUnknownItem):
+		status, msg = http.StatusNotFound, "Товар не найден"
+	case errors.Is(err, shop.ErrInvalidQuantity):
+		msg = "Некорректное количество"
+	case errors.Is(err, shop.ErrNotEnoughCoins):
+		msg = "Недостаточно монет"
+	case errors.Is(err, shop.ErrStockLimit):
+		msg = "Достигнут лимит этого товара"
+	case errors.Is(err, shop.ErrDailyLimit):
+		msg = "Дневной лимит покупок исчерпан"
+	case errors.Is(err, streak.ErrNothingToRepair):
+		msg = "Нет прерванного стрика"
+	case errors.Is(err, streak.ErrRepairExpired):
+		msg = "Время восстановления стрика истекло"
+	default:
+		status, msg = http.StatusInternalServerError, "Не удалось совершить покупку"
+	}
+	c.JSON(status, gin.H{"error": msg})
+	return shop.Result{}, false
+}
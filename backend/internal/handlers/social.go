@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/leaderboard"
+	"TalUpBackend/internal/middleware"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/social"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxSearchResults = 20
+
+func SearchUsers(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Пустой поисковый запрос"})
+		return
+	}
+
+	users, err := social.Search(db.DB, query, maxSearchResults)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить поиск"})
+		return
+	}
+	friends, err := social.FriendIDs(db.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить поиск"})
+		return
+	}
+
+	results := make([]gin.H, 0, len(users))
+	for _, u := range users {
+		if u.ID != user.ID {
+			results = append(results, userCard(c, user.ID, friends, u))
+		}
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GetUserProfile shows another user's profile. Progress is only included
+// when the owner's privacy settings allow the caller to see it.
+func GetUserProfile(c *gin.Context) {
+	viewer, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var owner models.User
+	if err := db.DB.First(&owner, id).Error; err != nil {
+		socialError(c, err)
+		return
+	}
+	visible, err := social.CanView(db.DB, viewer.ID, owner)
+	if err != nil {
+		socialError(c, err)
+		return
+	}
+
+	var rel struct {
+		Friend    bool
+		Following bool
+		Followers int64
+		Follows   int64
+	}
+	err = db.DB.Raw(`SELECT
+		EXISTS (SELECT 1 FROM friendships WHERE user_id = ? AND friend_id = ?) AS friend,
+		EXISTS (SELECT 1 FROM follows WHERE follower_id = ? AND followee_id = ?) AS following,
+		(SELECT COUNT(*) FROM follows WHERE followee_id = ?) AS followers,
+		(SELECT COUNT(*) FROM follows WHERE follower_id = ?) AS follows`,
+		viewer.ID, owner.ID, viewer.ID, owner.ID, owner.ID, owner.ID).Scan(&rel).Error
+	if err != nil {
+		socialError(c, err)
+		return
+	}
+
+	profile := userCard(c, viewer.ID, map[uint]bool{owner.ID: rel.Friend}, owner)
+	profile["isFriend"] = rel.Friend
+	profile["isFollowing"] = rel.Following
+	if visible {
+		profile["level"] = owner.Level
+		profile["xp"] = owner.Xp
+		profile["streak"] = owner.StreakCount
+		profile["learnedWords"] = owner.LearnedWords
+		profile["treePhase"] = owner.TreePhase
+		profile["league"] = owner.League
+		profile["leagueName"] = leaderboard.LeagueName(owner.League)
+		profile["followers"] = rel.Followers
+		profile["following"] = rel.Follows
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+func FollowUser(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	if err := social.Follow(db.DB, user.ID, id); err != nil {
+		socialError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Вы подписались"})
+}
+
+func UnfollowUser(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	if err := social.Unfollow(db.DB, user.ID, id); err != nil {
+		socialError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Вы отписались"})
+}
+
+// GetFollows lists who the caller follows and who follows them.
+func GetFollows(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var following, followers []models.User
+	err := db.DB.Where("id IN (?)", db.DB.Model(&models.Follow{}).Select("followee_id").Where("follower_id = ?", user.ID)).
+		Order("name, id").Find(&following).Error
+	if err == nil {
+		err = db.DB.Where("id IN (?)", db.DB.Model(&models.Follow{}).Select("follower_id").Where("followee_id = ?", user.ID)).
+			Order("name, id").Find(&followers).Error
+	}
+	if err != nil {
+		socialError(c, err)
+		return
+	}
+	friends, err := social.FriendIDs(db.DB, user.ID)
+	if err != nil {
+		socialError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"following": userCards(c, user.ID, friends, following),
+		"followers": userCards(c, user.ID, friends, followers),
+	})
+}
+
+func GetFriends(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	friends, err := social.Friends(db.DB, user.ID)
+	if err != nil {
+		socialError(c, err)
+		return
+	}
+	friendIDs := make(map[uint]bool, len(friends))
+	for _, f := range friends {
+		friendIDs[f.ID] = true
+	}
+	c.JSON(http.StatusOK, userCards(c, user.ID, friendIDs, friends))
+}
+
+func RemoveFriend(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	if err := social.Unfriend(db.DB, user.ID, id); err != nil {
+		socialError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Пользователь удалён из друзей"})
+}
+
+// GetFriendRequests returns the caller's pending requests, both received
+// and sent, together with the other user of each.
+func GetFriendRequests(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var requests []models.FriendRequest
+	if err := db.DB.Where("status = ? AND (from_id = ? OR to_id = ?)", models.FriendRequestPending, user.ID, user.ID).
+		Order("created_at DESC").Find(&requests).Error; err != nil {
+		socialError(c, err)
+		return
+	}
+
+	ids := make([]uint, 0, len(requests))
+	for _, r := range requests {
+		ids = append(ids, r.FromID, r.ToID)
+	}
+	var users []models.User
+	if len(ids) > 0 {
+		if err := db.DB.Where("id IN ?", ids).Find(&users).Error; err != nil {
+			socialError(c, err)
+			return
+		}
+	}
+	byID := make(map[uint]models.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	friends, err := social.FriendIDs(db.DB, user.ID)
+	if err != nil {
+		socialError(c, err)
+		return
+	}
+
+	incoming, outgoing := []gin.H{}, []gin.H{}
+	for _, r := range requests {
+		if r.ToID == user.ID {
+			incoming = append(incoming, gin.H{"request": r, "user": userCard(c, user.ID, friends, byID[r.FromID])})
+		} else {
+			outgoing = append(outgoing, gin.H{"request": r, "user": userCard(c, user.ID, friends, byID[r.ToID])})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"incoming": incoming, "outgoing": outgoing})
+}
+
+func SendFriendRequest(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		UserID uint `json:"userId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные запроса"})
+		return
+	}
+
+	req, err := social.SendRequest(db.DB, user.ID, body.UserID)
+	if err != nil {
+		socialError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+func AcceptFriendRequest(c *gin.Context) {
+	respondFriendRequest(c, true)
+}
+
+func DeclineFriendRequest(c *gin.Context) {
+	respondFriendRequest(c, false)
+}
+
+func respondFriendRequest(c *gin.Context, accept bool) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	req, err := social.Respond(db.DB, user.ID, id, accept)
+	if err != nil {
+		socialError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+func socialError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, social.ErrUserNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+	case errors.Is(err, social.ErrRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Заявка не найдена"})
+	case errors.Is(err, social.ErrSelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя выбрать самого себя"})
+	case errors.Is(err, social.ErrAlreadyFriends):
+		c.JSON(http.StatusConflict, gin.H{"error": "Вы уже друзья"})
+	case errors.Is(err, social.ErrAlreadyRequested):
+		c.JSON(http.StatusConflict, gin.H{"error": "Заявка уже отправлена"})
+	case errors.Is(err, social.ErrRequestsClosed):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Пользователь не принимает заявки в друзья"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос. Попробуйте позже"})
+	}
+}
+
+func userCards(c *gin.Context, viewerID uint, friends map[uint]bool, users []models.User) []gin.H {
+	out := make([]gin.H, 0, len(users))
+	for _, u := range users {
+		out = append(out, userCard(c, viewerID, friends, u))
+	}
+	return out
+}
+
+// userCard introduces u to the viewer. The name and avatar are masked like
+// on the leaderboard when u's profile is hidden from the viewer; friends are
+// the viewer's friend ids.
+func userCard(c *gin.Context, viewerID uint, friends map[uint]bool, u models.User) gin.H {
+	hidden := !social.Visible(viewerID, u.ID, u.ProfileVisibility, friends)
+	name, avatar := u.Name, avatarURL(c, u.Avatar)
+	if hidden {
+		name, avatar = hiddenUserName, ""
+	}
+	return gin.H{
+		"id":       u.ID,
+		"username": u.Username,
+		"name":     name,
+		"avatar":   avatar,
+		"hidden":   hidden,
+	}
+}
+
+func avatarURL(c *gin.Context, avatar string) string {
+	if avatar != "" && avatar[0] == '/' {
+		return fmt.Sprintf("http://%s%s", c.Request.Host, avatar)
+	}
+	return avatar
+}
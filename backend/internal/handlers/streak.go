@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/achievements"
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/middleware"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/rewards"
+	"TalUpBackend/internal/shop"
+	"TalUpBackend/internal/streak"
+	"TalUpBackend/internal/wallet"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rollover applies the daily rollover to the user, spends and records the
+// streak freezes it used, pays a due streak reward and notes balance changes
+// in the ledger. It runs inside updateUser, which saves the user.
+func rollover(tx *gorm.DB, user *models.User, ledger *wallet.Ledger) ([]streak.Event, error) {
+	freezes, err := shop.Quantity(tx, user.ID, models.ItemStreakFreeze)
+	if err != nil {
+		return nil, err
+	}
+
+	now := user.LocalNow()
+	before := *user
+	events := streak.Rollover(user, freezes, now)
+	for _, e := range events {
+		if e.Kind != streak.EventStreakFrozen {
+			continue
+		}
+		err := shop.Consume(tx, user.ID, models.ItemStreakFreeze, e.Value)
+		if errors.Is(err, shop.ErrNotEnoughItems) {
+			// The freezes are gone after all, so the streak breaks.
+			*user = before
+			events = streak.Rollover(user, 0, now)
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := recordFrozenDays(tx, user.ID, models.FreezeSourceFreeze, e.Days); err != nil {
+			return nil, err
+		}
+	}
+
+	extended := false
+	paid := events[:0]
+	for _, e := range events {
+		switch e.Kind {
+		case streak.EventStreakExtended:
+			extended = true
+		case streak.EventInactivityPenalty:
+			ledger.Record(wallet.TreeXp, -e.Value, wallet.ReasonInactivity, wallet.NoSource)
+		case streak.EventStreakReward:
+			granted, err := rewards.Grant(tx, user, ledger, rewards.Streak, now.Format("2006-01-02"))
+			if err != nil {
+				return nil, err
+			}
+			if !granted {
+				continue
+			}
+			streak.RecalculateTreePhase(user)
+		}
+		paid = append(paid, e)
+	}
+	events = paid
+	if extended {
+		if _, err := achievements.Evaluate(tx, user, ledger, achievements.EventStreakExtended); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+func recordFrozenDays(tx *gorm.DB, userID uint, source string, days []string) error {
+	if len(days) == 0 {
+		return nil
+	}
+	rows := make([]models.StreakFreezeDay, 0, len(days))
+	for _, day := range days {
+		rows = append(rows, models.StreakFreezeDay{UserID: userID, Day: day, Source: source})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+func GetStreakFreezes(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	freezes, err := shop.Quantity(db.DB, user.ID, models.ItemStreakFreeze)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить историю заморозок"})
+		return
+	}
+
+	var days []models.StreakFreezeDay
+	if err := db.DB.Where("user_id = ?", user.ID).Order("day DESC").Find(&days).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить историю заморозок"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"freezes":        freezes,
+		"brokenStreak":   user.BrokenStreak,
+		"brokenStreakOn": user.BrokenStreakOn,
+		"frozenDays":     days,
+	})
+}
@@ -1,98 +1,40 @@
 package handlers
 
 import (
+	"TalUpBackend/internal/achievements"
+	"TalUpBackend/internal/asr"
 	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/distractors"
+	"TalUpBackend/internal/middleware"
 	"TalUpBackend/internal/models"
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"TalUpBackend/internal/pronunciation"
+	"TalUpBackend/internal/srs"
+	"TalUpBackend/internal/streak"
+	"TalUpBackend/internal/wallet"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"errors"
 	"io"
-	"io/ioutil"
+	"log"
 	"math/rand"
-	"mime/multipart"
 	"net/http"
-	"os"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-type WordEntry struct {
-	ID   int    `json:"id"`
-	Word string `json:"word"`
-}
-
-var baseWords map[uint]string
-
-func levenshteinSimilarity(a, b string) float64 {
-	la := len(a)
-	lb := len(b)
-	if la == 0 || lb == 0 {
-		return 0
-	}
-	d := make([][]int, la+1)
-	for i := range d {
-		d[i] = make([]int, lb+1)
-	}
-	for i := 0; i <= la; i++ {
-		d[i][0] = i
-	}
-	for j := 0; j <= lb; j++ {
-		d[0][j] = j
-	}
-	for i := 1; i <= la; i++ {
-		for j := 1; j <= lb; j++ {
-			cost := 0
-			if a[i-1] != b[j-1] {
-				cost = 1
-			}
-			d[i][j] = min(
-				d[i-1][j]+1,
-				min(d[i][j-1]+1, d[i-1][j-1]+cost),
-			)
-		}
-	}
-	dist := d[la][lb]
-	maxLen := la
-	if lb > la {
-		maxLen = lb
-	}
-	return 1.0 - float64(dist)/float64(maxLen)
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-func LoadBaseWords() {
-	baseWords = make(map[uint]string)
-	data, err := ioutil.ReadFile("data/words.json")
-	if err != nil {
-		panic("Не удалось загрузить words.json: " + err.Error())
-	}
-
-	var words []WordEntry
-	if err := json.Unmarshal(data, &words); err != nil {
-		panic("Ошибка парсинга words.json: " + err.Error())
-	}
-
-	for _, w := range words {
-		baseWords[uint(w.ID)] = w.Word
-	}
-	fmt.Printf("Загружено слов: %d\n", len(baseWords))
-}
-
 type Task struct {
-	ID                string   `json:"id"`
-	WordID            uint     `json:"word_id"`
-	MaskedSentence    string   `json:"masked_sentence"`
-	Sentence          string   `json:"sentence"`
-	CorrectAnswer     string   `json:"correct_answer"`
+	ID             string `json:"id"`
+	WordID         uint   `json:"word_id"`
+	MaskedSentence string `json:"masked_sentence"`
+	Sentence       string `json:"sentence"`
+	// CorrectAnswer never leaves the server; the expected answer is kept in
+	// the issued task and revealed only after the task is submitted.
+	CorrectAnswer     string   `json:"-"`
 	Translation       string   `json:"translation"`
 	Difficulty        string   `json:"difficulty"`
 	Options           []string `json:"options"`
@@ -102,33 +44,25 @@ type Task struct {
 }
 
 type SubmitInput struct {
-	WordID   uint   `json:"word_id"`
-	Success  bool   `json:"success"`
-	TaskType string `json:"task_type"`
+	TaskID  string   `json:"task_id" binding:"required"`
+	Answer  string   `json:"answer"`
+	Answers []string `json:"answers"`
 }
 
-func callModel(masked, correct string) []string {
-	payload := map[string]string{
-		"text":    masked,
-		"correct": correct,
-	}
-	body, _ := json.Marshal(payload)
-	resp, err := http.Post("http://127.0.0.1:8000/predict/", "application/json", bytes.NewBuffer(body))
-	if err != nil {
-		fmt.Println("Ошибка запроса к модели")
-		return nil
-	}
-	defer resp.Body.Close()
-	respBody, _ := ioutil.ReadAll(resp.Body)
-	var data map[string][]string
-	json.Unmarshal(respBody, &data)
-	clean := []string{}
-	for _, word := range data["words"] {
-		if trimmed := strings.TrimSpace(word); trimmed != "" {
-			clean = append(clean, trimmed)
+func answersByDifficulty(difficulty string) []string {
+	answers := []string{}
+	for _, s := range contentRepo.Sentences() {
+		if s.Difficulty == difficulty {
+			answers = append(answers, s.CorrectAnswer)
 		}
 	}
-	return clean
+	return answers
+}
+
+func withOptions(suggestions []string, correct string) []string {
+	all := append(append([]string{}, suggestions...), correct)
+	rand.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+	return all
 }
 
 func convertLevel(level string) string {
@@ -149,34 +83,69 @@ func isBetween(value, low, high string) bool {
 	return order[value] >= order[low] && order[value] <= order[high]
 }
 
-var tasks []Task
-
-func LoadTasks() {
-	file, err := ioutil.ReadFile("data/tasks_for_model.json")
-	if err != nil {
-		panic("Failed to load tasks JSON: " + err.Error())
+func taskFromSentence(s models.Sentence) Task {
+	return Task{
+		WordID:            s.WordID,
+		MaskedSentence:    s.MaskedSentence,
+		CorrectAnswer:     s.CorrectAnswer,
+		Translation:       s.Translation,
+		Difficulty:        s.Difficulty,
+		TranslationTarget: s.TranslationTarget,
+		Text:              s.Text,
 	}
-	json.Unmarshal(file, &tasks)
-	fmt.Printf("Загружено заданий: %d\n", len(tasks))
 }
 
-func generateTaskID(wordID uint) string {
-	return fmt.Sprintf("%d_%d", time.Now().UnixNano(), wordID)
+// generateTaskID returns an unguessable ID, so a task can only be submitted
+// by the client it was issued to.
+func generateTaskID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
 func GetNextTask(c *gin.Context) {
-	userData, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	current, ok := middleware.CurrentUser(c)
+	if !ok {
 		return
 	}
-	user := userData.(models.User)
 
+	user, err := refreshLives(current.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить жизни"})
+		return
+	}
 	if user.Lives+user.BonusLives <= 0 {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно жизней"})
 		return
 	}
 
+	selectedTasks, err := selectTasks(c.Request.Context(), user, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue tasks"})
+		return
+	}
+	if selectedTasks == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no suitable tasks found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, selectedTasks)
+}
+
+const batchSize = 10
+
+// typesPerWord lists the tasks built for every word picked for a batch.
+var typesPerWord = []string{"standard", "word_translation", "sentence_shuffle", "asr_reading"}
+
+// wordsPerBatch is how many words fill a batch: ceil(batchSize / len(typesPerWord)).
+var wordsPerBatch = (batchSize + len(typesPerWord) - 1) / len(typesPerWord)
+
+// selectTasks builds a batch of up to batchSize tasks: due reviews first, then new
+// words within the daily budget. Each task is issued for later grading. It
+// returns nil when no word fits the user's level range.
+func selectTasks(ctx context.Context, user models.User, sessionID *uint) ([]Task, error) {
 	minLevel := convertLevel(user.CurrentLevel)
 	maxLevel := convertLevel(user.AimLevel)
 
@@ -187,119 +156,144 @@ func GetNextTask(c *gin.Context) {
 		progressMap[uw.WordID] = uw
 	}
 
-	var learningTasks, newTasks, learnedTasks []Task
-
-	for _, t := range tasks {
-		if !isBetween(t.Difficulty, minLevel, maxLevel) {
+	now := user.LocalNow()
+	tasksByWord := make(map[uint][]Task)
+	var wordOrder []uint
+	for _, s := range contentRepo.Sentences() {
+		if !isBetween(s.Difficulty, minLevel, maxLevel) {
 			continue
 		}
-		uw, exists := progressMap[t.WordID]
-		if exists {
-			switch uw.Status {
-			case "learning":
-				learningTasks = append(learningTasks, t)
-			case "learned":
-				learnedTasks = append(learnedTasks, t)
-			default:
-				newTasks = append(newTasks, t)
-			}
-		} else {
-			newTasks = append(newTasks, t)
+		t := taskFromSentence(s)
+		if _, seen := tasksByWord[t.WordID]; !seen {
+			wordOrder = append(wordOrder, t.WordID)
 		}
+		tasksByWord[t.WordID] = append(tasksByWord[t.WordID], t)
 	}
 
-	candidateTasks := append(learningTasks, newTasks...)
+	var dueWords, newWords []uint
+	for _, wordID := range wordOrder {
+		uw, exists := progressMap[wordID]
+		if !exists {
+			newWords = append(newWords, wordID)
+		} else if srs.IsDue(uw, now) {
+			dueWords = append(dueWords, wordID)
+		}
+	}
+
+	sort.SliceStable(dueWords, func(i, j int) bool {
+		return progressMap[dueWords[i]].DueAt.Before(progressMap[dueWords[j]].DueAt)
+	})
+
+	var introducedToday int64
+	db.DB.Model(&models.UserWord{}).
+		Where("user_id = ? AND created_at >= ?", user.ID, srs.StartOfDay(now)).
+		Count(&introducedToday)
 
-	if rand.Float64() < 0.2 {
-		candidateTasks = append(candidateTasks, learnedTasks...)
+	newBudget := srs.DailyNewBudget - int(introducedToday)
+	if newBudget < 0 {
+		newBudget = 0
+	}
+	rand.Shuffle(len(newWords), func(i, j int) { newWords[i], newWords[j] = newWords[j], newWords[i] })
+	if len(newWords) > newBudget {
+		newWords = newWords[:newBudget]
+	}
+
+	// Each word yields up to one task of every type, so a full batch needs
+	// only wordsPerBatch words. Distractors are fetched only for these.
+	candidateTasks := []Task{}
+	for _, wordID := range append(dueWords, newWords...) {
+		if len(candidateTasks) >= wordsPerBatch {
+			break
+		}
+		sentences := tasksByWord[wordID]
+		t := sentences[rand.Intn(len(sentences))]
+		if strings.TrimSpace(t.CorrectAnswer) == "" {
+			continue
+		}
+		candidateTasks = append(candidateTasks, t)
 	}
 
 	if len(candidateTasks) == 0 {
-		fmt.Println("Нет подходящих заданий")
-		c.JSON(http.StatusNotFound, gin.H{"error": "no suitable tasks found"})
-		return
+		return nil, nil
 	}
 
-	sort.Slice(candidateTasks, func(i, j int) bool {
-		uw1 := progressMap[candidateTasks[i].WordID]
-		uw2 := progressMap[candidateTasks[j].WordID]
-		return uw1.Coefficient < uw2.Coefficient
-	})
+	requests := make([]distractors.Request, 0, len(candidateTasks))
+	for _, t := range candidateTasks {
+		requests = append(requests, distractors.Request{
+			MaskedSentence: t.MaskedSentence,
+			CorrectAnswer:  strings.TrimSpace(t.CorrectAnswer),
+			Difficulty:     t.Difficulty,
+		})
+	}
+	suggestionsBySentence := distractors.FetchAll(ctx, distractorSource, requests, 4)
 
-	rand.Seed(time.Now().UnixNano())
 	selectedTasks := []Task{}
-	typesPerWord := []string{"standard", "word_translation", "sentence_shuffle", "asr_reading"}
-
 	for _, t := range candidateTasks {
 		for _, typ := range typesPerWord {
-			if len(selectedTasks) >= 10 {
+			if len(selectedTasks) >= batchSize {
 				break
 			}
 
 			correct := strings.TrimSpace(t.CorrectAnswer)
-			if correct == "" {
-				continue
-			}
 
 			task := t
-			task.ID = generateTaskID(t.WordID)
+			id, err := generateTaskID()
+			if err != nil {
+				return nil, err
+			}
+			task.ID = id
 			task.Type = typ
+			task.Text = ""
+			expected := correct
 
 			switch typ {
 			case "standard":
-				suggestions := callModel(t.MaskedSentence, correct)
+				suggestions := suggestionsBySentence[t.MaskedSentence]
 				if len(suggestions) == 0 {
-					fmt.Println("Нет вариантов от модели для типа:", typ, "слово:", t.CorrectAnswer)
+					log.Printf("Нет вариантов ответа для типа %s, слово %q", typ, t.CorrectAnswer)
 					continue
 				}
-				all := append(suggestions, correct)
-				rand.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
-				task.Options = all
+				task.Options = withOptions(suggestions, correct)
 
 				task.Sentence = strings.Replace(t.MaskedSentence, "<mask>", "___", 1)
 
 			case "word_translation":
-				suggestions := callModel(t.MaskedSentence, correct)
+				suggestions := suggestionsBySentence[t.MaskedSentence]
 				if len(suggestions) == 0 {
-					fmt.Println("Нет вариантов от модели для типа:", typ, "слово:", t.CorrectAnswer)
+					log.Printf("Нет вариантов ответа для типа %s, слово %q", typ, t.CorrectAnswer)
 					continue
 				}
-				all := append(suggestions, correct)
-				rand.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
-				task.Options = all
+				task.Options = withOptions(suggestions, correct)
 				task.Sentence = ""
 
 			case "sentence_shuffle":
 				sentenceKazakh := strings.Replace(t.MaskedSentence, "<mask>", t.CorrectAnswer, 1)
-				words := strings.FieldsFunc(sentenceKazakh, func(r rune) bool {
-					return r == ' ' || r == '.' || r == ',' || r == '?' || r == '!'
-				})
+				words := shuffleTokens(sentenceKazakh)
 				if len(words) <= 1 {
-					fmt.Println("Слишком мало слов для шафла:", sentenceKazakh)
 					continue
 				}
-				for i, w := range words {
-					words[i] = strings.ToLower(strings.Trim(w, ".,!?:;"))
-				}
+				expected = strings.Join(words, " ")
 				rand.Shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
 				task.Options = words
-				task.CorrectAnswer = strings.ToLower(strings.TrimSpace(sentenceKazakh))
 				task.Sentence = t.Translation
 				break
 			case "asr_reading":
 				task.Sentence = ""
 				task.Text = t.Text
+				expected = t.Text
 			}
 
+			if err := issueTask(user.ID, sessionID, task, expected); err != nil {
+				return nil, err
+			}
 			selectedTasks = append(selectedTasks, task)
 		}
-		if len(selectedTasks) >= 10 {
+		if len(selectedTasks) >= batchSize {
 			break
 		}
 	}
 
-	fmt.Printf("Отобрано заданий: %d\n", len(selectedTasks))
-	c.JSON(http.StatusOK, selectedTasks)
+	return selectedTasks, nil
 }
 
 func SubmitAsrResult(c *gin.Context) {
@@ -309,53 +303,80 @@ func SubmitAsrResult(c *gin.Context) {
 		return
 	}
 
-	expected := c.PostForm("expected")
-	if expected == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing expected text"})
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		return
 	}
 
-	tempFile, err := ioutil.TempFile("", "upload-*.m4a")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create temp file"})
+	taskID := c.PostForm("task_id")
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing task_id"})
 		return
 	}
-	defer os.Remove(tempFile.Name())
-	if err := c.SaveUploadedFile(file, tempFile.Name()); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save file"})
+	var issued models.IssuedTask
+	if err := db.DB.Where("id = ? AND user_id = ? AND type = ?", taskID, user.ID, "asr_reading").First(&issued).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown task"})
+		return
+	}
+	if issued.ConsumedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "task already submitted"})
 		return
 	}
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, _ := writer.CreateFormFile("file", file.Filename)
-	fileContent, _ := os.Open(tempFile.Name())
-	io.Copy(part, fileContent)
-	writer.Close()
-	fmt.Println("Имя файла:", file.Filename)
-	fmt.Println("Размер:", file.Size)
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read file"})
+		return
+	}
+	audio, err := io.ReadAll(src)
+	src.Close()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read file"})
+		return
+	}
 
-	resp, err := http.Post("http://127.0.0.1:8001/transcribe", writer.FormDataContentType(), body)
+	transcript, err := speechRecognizer.Transcribe(c.Request.Context(), file.Filename, audio)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ошибка при отправке в модель"})
+		log.Println("Ошибка распознавания речи:", err)
+		switch {
+		case errors.Is(err, asr.ErrTimeout):
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "распознавание речи не ответило вовремя"})
+		case errors.Is(err, asr.ErrBadResponse):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "некорректный ответ сервиса распознавания речи"})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "сервис распознавания речи недоступен"})
+		}
 		return
 	}
-	defer resp.Body.Close()
 
-	var result map[string]string
-	json.NewDecoder(resp.Body).Decode(&result)
-	predicted := strings.ToLower(strings.TrimSpace(result["text"]))
-	expected = strings.ToLower(strings.TrimSpace(expected))
+	predicted := strings.ToLower(strings.TrimSpace(transcript))
+	expected := strings.ToLower(strings.TrimSpace(issued.ExpectedAnswer))
 
-	similarity := levenshteinSimilarity(predicted, expected)
-	isCorrect := similarity >= 0.75
+	result := pronunciation.Score(expected, predicted)
+	threshold := pronunciationCfg.Threshold(issued.Difficulty)
+	isCorrect := result.Score >= threshold
 
-	c.JSON(http.StatusOK, gin.H{"correct": isCorrect, "transcribed": predicted})
+	if err := consumeReading(issued, isCorrect, result.Score); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "task already submitted"})
+		return
+	}
+	user, unlocked, err := recordResult(user.ID, issued, isCorrect)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record result"})
+		return
+	}
 
-	fmt.Println("Отправка файла на модель:", file.Filename)
-	fmt.Println("Ожидаемый текст:", expected)
-	fmt.Println("Ответ от модели:", predicted)
-	fmt.Println("Совпадает ли:", isCorrect)
+	c.JSON(http.StatusOK, gin.H{
+		"correct":      isCorrect,
+		"transcribed":  predicted,
+		"score":        result.Score,
+		"threshold":    threshold,
+		"words":        result.Words,
+		"achievements": unlocked,
+		"lives":        user.Lives,
+		"bonusLives":   user.BonusLives,
+		"totalLives":   user.Lives + user.BonusLives,
+	})
 }
 
 func SubmitResult(c *gin.Context) {
@@ -365,220 +386,257 @@ func SubmitResult(c *gin.Context) {
 		return
 	}
 
-	userData, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		return
 	}
-	user := userData.(models.User)
-
-	var uw models.UserWord
-	err := db.DB.Where("user_id = ? AND word_id = ?", user.ID, input.WordID).First(&uw).Error
-	isNew := err != nil
-	prevStatus := uw.Status
-
-	if isNew {
-		uw = models.UserWord{
-			UserID:               user.ID,
-			WordID:               input.WordID,
-			Repeats:              0,
-			Mistakes:             0,
-			Status:               "new",
-			TaskTypesPassed:      "",
-			RepeatsStandard:      0,
-			RepeatsTranslation:   0,
-			RepeatsShuffle:       0,
-			CompletedStandard:    false,
-			CompletedTranslation: false,
-			CompletedShuffle:     false,
+
+	issued, err := consumeTask(user.ID, input.TaskID)
+	if err != nil {
+		switch err {
+		case errUnknownTask:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errTaskConsumed:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errReadingTask:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load task"})
 		}
+		return
 	}
 
-	if input.Success {
-		uw.Repeats++
+	success, err := gradeAnswer(issued, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		switch input.TaskType {
-		case "standard":
-			uw.RepeatsStandard++
-		case "word_translation":
-			uw.RepeatsTranslation++
-		case "sentence_shuffle":
-			uw.RepeatsShuffle++
-		case "asr_reading":
-			uw.RepeatsAsr++
-		}
+	user, unlocked, err := recordResult(user.ID, issued, success)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record result"})
+		return
+	}
 
-		if !strings.Contains(uw.TaskTypesPassed, input.TaskType) {
-			if uw.TaskTypesPassed == "" {
-				uw.TaskTypesPassed = input.TaskType
-			} else {
-				uw.TaskTypesPassed += "," + input.TaskType
-			}
-		}
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "progress updated",
+		"correct":       success,
+		"correctAnswer": issued.ExpectedAnswer,
+		"lives":         user.Lives,
+		"bonusLives":    user.BonusLives,
+		"totalLives":    user.Lives + user.BonusLives,
+		"achievements":  unlocked,
+	})
+}
 
-		if uw.RepeatsStandard >= 3 && uw.Mistakes <= 1 {
-			uw.CompletedStandard = true
-		}
-		if uw.RepeatsTranslation >= 3 && uw.Mistakes <= 1 {
-			uw.CompletedTranslation = true
-		}
-		if uw.RepeatsShuffle >= 3 && uw.Mistakes <= 1 {
-			uw.CompletedShuffle = true
-		}
-		if uw.RepeatsAsr >= 3 && uw.Mistakes <= 1 {
-			uw.CompletedAsr = true
-		}
+// recordResult applies a graded answer to the word's progress and the
+// user's counters under the user's row lock. It also returns the
+// achievements the answer unlocked.
+func recordResult(userID uint, issued models.IssuedTask, success bool) (models.User, []achievements.Achievement, error) {
+	wordID, taskType := issued.WordID, issued.Type
+	src := wallet.Source{Type: "task", ID: issued.ID}
 
-	} else {
-		uw.Mistakes++
+	var unlocked []achievements.Achievement
+	user, err := updateUser(userID, func(tx *gorm.DB, user *models.User, ledger *wallet.Ledger) error {
+		lifePool.Refill(user, ledger, time.Now())
 
-		user.TreeXp -= 1
-		if user.TreeXp < 0 {
-			user.TreeXp = 0
+		if err := tx.Model(&models.IssuedTask{}).Where("id = ?", issued.ID).Update("correct", success).Error; err != nil {
+			return err
 		}
 
-		if user.Lives > 0 {
-			user.Lives -= 1
-			if user.LifeRestoreAt.IsZero() {
-				user.LifeRestoreAt = time.Now()
+		var uw models.UserWord
+		err := tx.Where("user_id = ? AND word_id = ?", user.ID, wordID).Limit(1).Find(&uw).Error
+		if err != nil {
+			return err
+		}
+		isNew := uw.ID == 0
+		prevStatus := uw.Status
+
+		if isNew {
+			uw = models.UserWord{
+				UserID:               user.ID,
+				WordID:               wordID,
+				Repeats:              0,
+				Mistakes:             0,
+				Status:               "new",
+				TaskTypesPassed:      "",
+				RepeatsStandard:      0,
+				RepeatsTranslation:   0,
+				RepeatsShuffle:       0,
+				CompletedStandard:    false,
+				CompletedTranslation: false,
+				CompletedShuffle:     false,
+				Ease:                 srs.DefaultEase,
 			}
-		} else if user.BonusLives > 0 {
-			user.BonusLives -= 1
 		}
-		db.DB.Save(&user)
 
-		if uw.Mistakes >= 2 {
-			switch input.TaskType {
+		if success {
+			uw.Repeats++
+
+			switch taskType {
 			case "standard":
-				uw.CompletedStandard = false
+				uw.RepeatsStandard++
 			case "word_translation":
-				uw.CompletedTranslation = false
+				uw.RepeatsTranslation++
 			case "sentence_shuffle":
-				uw.CompletedShuffle = false
+				uw.RepeatsShuffle++
 			case "asr_reading":
-				uw.CompletedAsr = false
+				uw.RepeatsAsr++
 			}
-		}
 
-		db.DB.Save(&user)
+			if !strings.Contains(uw.TaskTypesPassed, taskType) {
+				if uw.TaskTypesPassed == "" {
+					uw.TaskTypesPassed = taskType
+				} else {
+					uw.TaskTypesPassed += "," + taskType
+				}
+			}
 
-	}
+			if uw.RepeatsStandard >= 3 && uw.Mistakes <= 1 {
+				uw.CompletedStandard = true
+			}
+			if uw.RepeatsTranslation >= 3 && uw.Mistakes <= 1 {
+				uw.CompletedTranslation = true
+			}
+			if uw.RepeatsShuffle >= 3 && uw.Mistakes <= 1 {
+				uw.CompletedShuffle = true
+			}
+			if uw.RepeatsAsr >= 3 && uw.Mistakes <= 1 {
+				uw.CompletedAsr = true
+			}
 
-	if input.Success {
-		if uw.RepeatsStandard >= 3 &&
-			uw.RepeatsTranslation >= 3 &&
-			uw.RepeatsShuffle >= 3 &&
-			uw.RepeatsAsr >= 3 &&
-			uw.Mistakes <= 1 &&
-			uw.Coefficient >= 1 {
-			uw.Status = "learned"
 		} else {
-			uw.Status = "learning"
+			uw.Mistakes++
+
+			ledger.Add(wallet.TreeXp, -1, wallet.ReasonMistake, src)
+
+			lifePool.Spend(user, ledger, src, time.Now())
+
+			if uw.Mistakes >= 2 {
+				switch taskType {
+				case "standard":
+					uw.CompletedStandard = false
+				case "word_translation":
+					uw.CompletedTranslation = false
+				case "sentence_shuffle":
+					uw.CompletedShuffle = false
+				case "asr_reading":
+					uw.CompletedAsr = false
+				}
+			}
+
 		}
-	} else {
-		if uw.Mistakes >= 3 {
-			uw.Status = "mistaken"
+
+		if success {
+			if uw.RepeatsStandard >= 3 &&
+				uw.RepeatsTranslation >= 3 &&
+				uw.RepeatsShuffle >= 3 &&
+				uw.RepeatsAsr >= 3 &&
+				uw.Mistakes <= 1 &&
+				uw.Coefficient >= 1 {
+				uw.Status = "learned"
+			} else {
+				uw.Status = "learning"
+			}
 		} else {
-			uw.Status = "learning"
+			if uw.Mistakes >= 3 {
+				uw.Status = "mistaken"
+			} else {
+				uw.Status = "learning"
+			}
 		}
-	}
 
-	if input.Success {
-		uw.Coefficient += 0.2
-	} else {
-		uw.Coefficient -= 0.1
-	}
-	if uw.Coefficient > 1 {
-		uw.Coefficient = 1
-	}
-	if uw.Coefficient < 0 {
-		uw.Coefficient = 0
-	}
-
-	uw.LastSeen = time.Now().Format("2006-01-02")
-
-	if isNew {
-		db.DB.Create(&uw)
-	} else {
-		db.DB.Save(&uw)
-	}
-
-	if input.Success {
-		xpMap := map[string]float64{
-			"standard":         1.5,
-			"word_translation": 1,
-			"sentence_shuffle": 2,
-			"asr_reading":      0.5,
+		if success {
+			uw.Coefficient += 0.2
+		} else {
+			uw.Coefficient -= 0.1
 		}
-		levelXpReward := int(float64(xpMap[input.TaskType]) * 1.2)
-
-		treeXpRewardMap := map[string]float64{
-			"standard":         1.5,
-			"word_translation": 1,
-			"sentence_shuffle": 2,
-			"asr_reading":      0.5,
+		if uw.Coefficient > 1 {
+			uw.Coefficient = 1
 		}
-		treeXpReward := int(treeXpRewardMap[input.TaskType])
-
-		user.TreeXp += treeXpReward
-		user.Xp += levelXpReward
-
-		today := time.Now().Format("2006-01-02")
-
-		dailyGoal := 5
-		switch user.Time {
-		case "one":
-			dailyGoal = 3
-		case "two":
-			dailyGoal = 5
-		case "three":
-			dailyGoal = 8
-		case "more":
-			dailyGoal = 12
+		if uw.Coefficient < 0 {
+			uw.Coefficient = 0
 		}
 
-		if user.TodayLearnedWords >= dailyGoal && user.LastDailyGoalReward != today {
-			user.TreeXp += dailyGoal
-			user.LastDailyGoalReward = today
+		now := user.LocalNow()
+		srs.Review(&uw, success, now)
+		uw.LastSeen = now.Format("2006-01-02")
+
+		if isNew {
+			err = tx.Create(&uw).Error
+		} else {
+			err = tx.Save(&uw).Error
+		}
+		if err != nil {
+			return err
 		}
 
-		RecalculateTreePhase(&user)
+		if success {
+			xpMap := map[string]float64{
+				"standard":         1.5,
+				"word_translation": 1,
+				"sentence_shuffle": 2,
+				"asr_reading":      0.5,
+			}
+			levelXpReward := int(float64(xpMap[taskType]) * 1.2)
 
-		if user.Xp >= user.MaxXp {
-			user.Xp = 0
-			user.Level++
-			user.MaxXp += 20
-		}
-		if uw.Status == "learned" && (isNew || prevStatus != "learned") {
-			user.TodayLearnedWords++
-			user.Coins += 1
+			treeXpRewardMap := map[string]float64{
+				"standard":         1.5,
+				"word_translation": 1,
+				"sentence_shuffle": 2,
+				"asr_reading":      0.5,
+			}
+			treeXpReward := int(treeXpRewardMap[taskType])
+
+			ledger.Add(wallet.TreeXp, treeXpReward, wallet.ReasonTaskReward, src)
+			ledger.Add(wallet.Xp, levelXpReward, wallet.ReasonTaskReward, src)
+
+			applyLevelUp(user, ledger, src)
+			if uw.Status == "learned" && (isNew || prevStatus != "learned") {
+				user.TodayLearnedWords++
+				ledger.Add(wallet.Coins, 1, wallet.ReasonWordLearned, src)
+			}
+
+			if err := grantDailyGoal(tx, user, ledger); err != nil {
+				return err
+			}
+			streak.RecalculateTreePhase(user)
 		}
-	}
 
-	var totalLearning, totalLearned int64
-	db.DB.Model(&models.UserWord{}).Where("user_id = ? AND status = ?", user.ID, "learning").Count(&totalLearning)
-	db.DB.Model(&models.UserWord{}).Where("user_id = ? AND status = ?", user.ID, "learned").Count(&totalLearned)
+		var totalLearning, totalLearned int64
+		tx.Model(&models.UserWord{}).Where("user_id = ? AND status = ?", user.ID, "learning").Count(&totalLearning)
+		tx.Model(&models.UserWord{}).Where("user_id = ? AND status = ?", user.ID, "learned").Count(&totalLearned)
 
-	user.LearningWords = int(totalLearning)
-	user.LearnedWords = int(totalLearned)
-	db.DB.Save(&user)
+		user.LearningWords = int(totalLearning)
+		user.LearnedWords = int(totalLearned)
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":    "progress updated",
-		"lives":      user.Lives,
-		"bonusLives": user.BonusLives,
-		"totalLives": user.Lives + user.BonusLives,
+		events := []achievements.Event{achievements.EventAnswerRecorded}
+		if taskType == "asr_reading" {
+			events = append(events, achievements.EventReadingScored)
+		}
+		unlocked, err = achievements.Evaluate(tx, user, ledger, events...)
+		return err
 	})
+	return user, unlocked, err
+}
+
+// applyLevelUp moves the user to the next level once the level's XP is full.
+func applyLevelUp(user *models.User, ledger *wallet.Ledger, src wallet.Source) {
+	if user.Xp >= user.MaxXp {
+		ledger.Add(wallet.Xp, -user.Xp, wallet.ReasonLevelUp, src)
+		user.Level++
+		user.MaxXp += 20
+	}
 }
 
 func GetRandomWord(c *gin.Context) {
-	if len(tasks) == 0 {
+	sentences := contentRepo.Sentences()
+	if len(sentences) == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Задания не загружены"})
 		return
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	randomTask := tasks[rand.Intn(len(tasks))]
+	randomTask := sentences[rand.Intn(len(sentences))]
 
 	translation := randomTask.TranslationTarget
 	if translation == "" {
@@ -592,69 +650,29 @@ func GetRandomWord(c *gin.Context) {
 }
 
 func GetWordList(c *gin.Context) {
-	userData, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		return
 	}
-	user := userData.(models.User)
 
 	wordType := c.Query("type")
 
 	var userWords []models.UserWord
 	db.DB.Where("user_id = ? AND status = ?", user.ID, wordType).Find(&userWords)
 
-	wordIDs := map[uint]bool{}
-	for _, uw := range userWords {
-		wordIDs[uw.WordID] = true
-	}
+	sort.Slice(userWords, func(i, j int) bool { return userWords[i].WordID < userWords[j].WordID })
 
 	result := []gin.H{}
-	used := make(map[uint]bool)
-
-	for _, t := range tasks {
-		if wordIDs[t.WordID] && !used[t.WordID] {
-			result = append(result, gin.H{
-				"word":        baseWords[t.WordID],
-				"translation": t.TranslationTarget,
-			})
-			used[t.WordID] = true
+	for _, uw := range userWords {
+		word, ok := contentRepo.Word(uw.WordID)
+		if !ok {
+			continue
 		}
+		result = append(result, gin.H{
+			"word":        word.Word,
+			"translation": word.Translation,
+		})
 	}
 
 	c.JSON(http.StatusOK, result)
 }
-
-func BuyLife(c *gin.Context) {
-	userData, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-	user := userData.(models.User)
-
-	const cost = 5
-
-	if user.Coins < cost {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Недостаточно монет"})
-		return
-	}
-	if user.Lives >= 5 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "У вас уже максимум жизней"})
-		return
-	}
-
-	user.Coins -= cost
-	user.Lives += 1
-	if user.Lives > 5 {
-		user.Lives = 5
-	}
-
-	db.DB.Save(&user)
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Жизнь куплена!",
-		"lives":   user.Lives,
-		"coins":   user.Coins,
-	})
-}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/middleware"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/wallet"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+// updateUser re-reads the user under a row lock, lets fn change it and saves
+// the row together with fn's ledger entries in one transaction, so parallel
+// requests cannot overwrite each other's counters. The row is only written
+// when fn changed it.
+func updateUser(userID uint, fn func(tx *gorm.DB, user *models.User, ledger *wallet.Ledger) error) (models.User, error) {
+	var user models.User
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = updateUserTx(tx, userID, fn)
+		return err
+	})
+	return user, err
+}
+
+// updateUserTx is updateUser inside a transaction the caller already holds.
+func updateUserTx(tx *gorm.DB, userID uint, fn func(tx *gorm.DB, user *models.User, ledger *wallet.Ledger) error) (models.User, error) {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+		return user, err
+	}
+	before := user
+
+	ledger := wallet.New(&user)
+	if err := fn(tx, &user, ledger); err != nil {
+		return user, err
+	}
+	if reflect.DeepEqual(before, user) {
+		return user, nil
+	}
+	if err := user.SaveVersioned(tx); err != nil {
+		return user, err
+	}
+	return user, ledger.Flush(tx)
+}
+
+func GetWalletHistory(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	currency := c.Query("currency")
+	if currency != "" && !wallet.IsCurrency(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная валюта", "currencies": wallet.Currencies})
+		return
+	}
+	limit := defaultHistoryLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный limit"})
+			return
+		}
+		limit = min(n, maxHistoryLimit)
+	}
+	var before uint
+	if v := c.Query("before"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 0)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный before"})
+			return
+		}
+		before = uint(id)
+	}
+
+	entries, err := wallet.History(db.DB, user.ID, currency, before, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить историю"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"balances": gin.H{
+			wallet.Coins:      user.Coins,
+			wallet.Xp:         user.Xp,
+			wallet.TreeXp:     user.TreeXp,
+			wallet.Lives:      user.Lives,
+			wallet.BonusLives: user.BonusLives,
+		},
+	})
+}
+
+func ReconcileWallet(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить пользователя"})
+		return
+	}
+
+	mismatches, err := wallet.Reconcile(db.DB, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сверить баланс"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": user.ID, "ok": len(mismatches) == 0, "mismatches": mismatches})
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"TalUpBackend/internal/config"
+	"TalUpBackend/internal/db"
+	"TalUpBackend/internal/lives"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/wallet"
+	"TalUpBackend/migrations"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB connects db.DB to the database in TALUP_TEST_DATABASE_DSN and
+// migrates it. Tests that need Postgres are skipped without it, so a plain
+// go test does not run them; point the variable at a scratch database, e.g.
+//
+//	TALUP_TEST_DATABASE_DSN="host=localhost user=talup dbname=talup_test sslmode=disable" go test ./...
+func testDB(t *testing.T) {
+	t.Helper()
+	dsn := os.Getenv("TALUP_TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TALUP_TEST_DATABASE_DSN is not set")
+	}
+	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrations.Up(conn); err != nil {
+		t.Fatal(err)
+	}
+	db.DB = conn
+	if lifePool == nil {
+		lifePool = lives.NewPool(config.LivesConfig{Max: 5, RegenInterval: 15 * time.Minute})
+	}
+}
+
+func testUser(t *testing.T) models.User {
+	t.Helper()
+	name := fmt.Sprintf("wallet_test_%d", time.Now().UnixNano())
+	user := models.User{Username: name, Email: name + "@example.com", Lives: 100, Coins: 10}
+	if err := db.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := wallet.Opening(db.DB, &user, wallet.ReasonSignup); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.DB.Delete(&models.User{}, user.ID) })
+	return user
+}
+
+func TestUpdateUserParallel(t *testing.T) {
+	testDB(t)
+	user := testUser(t)
+
+	// Each worker plays one answered task: a reward on success, a lost life
+	// on a mistake.
+	const workers = 40
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(correct bool) {
+			defer wg.Done()
+			_, err := updateUser(user.ID, func(tx *gorm.DB, u *models.User, ledger *wallet.Ledger) error {
+				if correct {
+					ledger.Add(wallet.Coins, 3, wallet.ReasonTaskReward, wallet.NoSource)
+					ledger.Add(wallet.Xp, 5, wallet.ReasonTaskReward, wallet.NoSource)
+				} else {
+					ledger.Add(wallet.Lives, -1, wallet.ReasonMistake, wallet.NoSource)
+				}
+				return nil
+			})
+			errs <- err
+		}(i%2 == 0)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got models.User
+	if err := db.DB.First(&got, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if want := user.Coins + 3*workers/2; got.Coins != want {
+		t.Errorf("coins = %d, want %d", got.Coins, want)
+	}
+	if want := user.Xp + 5*workers/2; got.Xp != want {
+		t.Errorf("xp = %d, want %d", got.Xp, want)
+	}
+	if want := user.Lives - workers/2; got.Lives != want {
+		t.Errorf("lives = %d, want %d", got.Lives, want)
+	}
+	if got.Version != user.Version+workers {
+		t.Errorf("version = %d, want %d", got.Version, user.Version+workers)
+	}
+
+	mismatches, err := wallet.Reconcile(db.DB, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) > 0 {
+		t.Errorf("ledger does not match balances: %+v", mismatches)
+	}
+}
+
+func TestSubmitResultParallel(t *testing.T) {
+	testDB(t)
+	user := testUser(t)
+	// Keep the rewards below a level up, which would reset the XP.
+	if err := db.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("max_xp", 1000).Error; err != nil {
+		t.Fatal(err)
+	}
+	word := models.Word{Word: "кітап"}
+	if err := db.DB.Create(&word).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.DB.Where("user_id = ?", user.ID).Delete(&models.IssuedTask{})
+		db.DB.Where("user_id = ?", user.ID).Delete(&models.UserWord{})
+		db.DB.Delete(&word)
+	})
+
+	const tasks = 20
+	ids := make([]string, tasks)
+	for i := range ids {
+		id, err := generateTaskID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		task := Task{ID: id, WordID: word.ID, Type: "standard", Difficulty: "A"}
+		if err := issueTask(user.ID, nil, task, "кітап"); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = id
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/submit", func(c *gin.Context) { c.Set("user", user) }, SubmitResult)
+
+	// Every other answer is wrong. All of them hit the same user and word.
+	var wg sync.WaitGroup
+	codes := make(chan int, tasks)
+	for i, id := range ids {
+		answer := "кітап"
+		if i%2 == 1 {
+			answer = "үй"
+		}
+		wg.Add(1)
+		go func(id, answer string) {
+			defer wg.Done()
+			body, _ := json.Marshal(SubmitInput{TaskID: id, Answer: answer})
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(string(body)))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			codes <- w.Code
+		}(id, answer)
+	}
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", code, http.StatusOK)
+		}
+	}
+
+	const correct, wrong = tasks / 2, tasks / 2
+	var uws []models.UserWord
+	if err := db.DB.Where("user_id = ? AND word_id = ?", user.ID, word.ID).Find(&uws).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(uws) != 1 {
+		t.Fatalf("%d progress rows for one word, want 1", len(uws))
+	}
+	if uw := uws[0]; uw.Repeats != correct || uw.RepeatsStandard != correct || uw.Mistakes != wrong {
+		t.Errorf("repeats %d, standard %d, mistakes %d; want %d, %d, %d",
+			uw.Repeats, uw.RepeatsStandard, uw.Mistakes, correct, correct, wrong)
+	}
+
+	var got models.User
+	if err := db.DB.First(&got, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	var earned int
+	db.DB.Model(&models.LedgerEntry{}).
+		Where("user_id = ? AND currency = ? AND reason = ?", user.ID, wallet.Xp, wallet.ReasonTaskReward).
+		Select("COALESCE(SUM(amount), 0)").Scan(&earned)
+	// A correct standard answer earns int(1.5 * 1.2) = 1 XP.
+	if earned != correct {
+		t.Errorf("task XP = %d, want %d", earned, correct)
+	}
+	if want := user.Lives - wrong; got.Lives != want {
+		t.Errorf("lives = %d, want %d", got.Lives, want)
+	}
+	var pending int64
+	db.DB.Model(&models.IssuedTask{}).Where("user_id = ? AND consumed_at IS NULL", user.ID).Count(&pending)
+	if pending != 0 {
+		t.Errorf("%d tasks were not consumed", pending)
+	}
+
+	mismatches, err := wallet.Reconcile(db.DB, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) > 0 {
+		t.Errorf("ledger does not match balances: %+v", mismatches)
+	}
+}
+
+func TestSaveVersionedStale(t *testing.T) {
+	testDB(t)
+	user := testUser(t)
+
+	fresh, stale := user, user
+	fresh.Name = "fresh"
+	if err := fresh.SaveVersioned(db.DB); err != nil {
+		t.Fatal(err)
+	}
+	stale.Name = "stale"
+	if err := stale.SaveVersioned(db.DB); !errors.Is(err, models.ErrStaleUser) {
+		t.Fatalf("err = %v, want %v", err, models.ErrStaleUser)
+	}
+	if stale.Version != user.Version {
+		t.Errorf("failed save changed the version to %d", stale.Version)
+	}
+
+	var got models.User
+	if err := db.DB.First(&got, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "fresh" {
+		t.Errorf("name = %q, the stale save overwrote the row", got.Name)
+	}
+}
+
+func TestUpdateProfileStale(t *testing.T) {
+	testDB(t)
+	stale := testUser(t)
+
+	if _, err := updateUser(stale.ID, func(tx *gorm.DB, u *models.User, ledger *wallet.Ledger) error {
+		ledger.Add(wallet.Coins, 1, wallet.ReasonTaskReward, wallet.NoSource)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// Stands in for AuthMiddleware, which loaded the user before the update.
+	r.PUT("/profile", func(c *gin.Context) { c.Set("user", stale) }, UpdateProfile)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/profile", strings.NewReader(`{"name":"stale"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusConflict, w.Body)
+	}
+	var got models.User
+	if err := db.DB.First(&got, stale.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Coins != stale.Coins+1 || got.Name == "stale" {
+		t.Errorf("stale profile save overwrote the row: coins %d, name %q", got.Coins, got.Name)
+	}
+}
+
+func TestWalletHistoryPages(t *testing.T) {
+	testDB(t)
+	user := testUser(t)
+
+	// One flush writes all its entries with the same created_at.
+	ledger := wallet.New(&user)
+	for i := 0; i < 7; i++ {
+		ledger.Record(wallet.Coins, 1, wallet.ReasonTaskReward, wallet.NoSource)
+	}
+	if err := ledger.Flush(db.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	var all []models.LedgerEntry
+	if err := db.DB.Where("user_id = ?", user.ID).Find(&all).Error; err != nil {
+		t.Fatal(err)
+	}
+	seen := map[uint]bool{}
+	var before uint
+	for {
+		page, err := wallet.History(db.DB, user.ID, "", before, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, e := range page {
+			if seen[e.ID] {
+				t.Fatalf("entry %d is on two pages", e.ID)
+			}
+			seen[e.ID] = true
+		}
+		before = page[len(page)-1].ID
+	}
+	if len(seen) != len(all) {
+		t.Errorf("paged through %d entries, want %d", len(seen), len(all))
+	}
+}
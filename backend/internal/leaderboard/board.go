@@ -0,0 +1,171 @@
+package leaderboard
+
+import (
+	"TalUpBackend/internal/wallet"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+	PeriodAll   = "all"
+)
+
+var (
+	ErrUnknownPeriod = errors.New("leaderboard: unknown period")
+	ErrBadCursor     = errors.New("leaderboard: malformed cursor")
+)
+
+// Entry is one ranked user. Score is the XP earned in the period.
+// Visibility is the user's profile visibility, for masking their details.
+type Entry struct {
+	UserID     uint   `json:"id"`
+	Name       string `json:"name"`
+	Avatar     string `json:"avatar"`
+	Visibility string `json:"-"`
+	League     int    `json:"league"`
+	Score      int    `json:"score"`
+	Position   int    `json:"position"`
+}
+
+// Query selects a board. League narrows it to one league tier and
+// FriendsOf to a user and their friends; without either everybody is ranked.
+type Query struct {
+	Period    string
+	League    *int
+	FriendsOf uint
+	Now       time.Time
+}
+
+// WeekStart returns Monday 00:00 UTC of the week containing t. Boards are
+// global, so periods are counted in UTC rather than in users' time zones.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func periodStart(period string, now time.Time) (time.Time, error) {
+	switch period {
+	case PeriodWeek:
+		return WeekStart(now), nil
+	case PeriodMonth:
+		y, m, _ := now.UTC().Date()
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC), nil
+	case PeriodAll:
+		return time.Time{}, nil
+	}
+	return time.Time{}, ErrUnknownPeriod
+}
+
+// ranked returns the SQL and arguments of a subquery with every user of the
+// board, their score and their position. Ties are broken by user id so that
+// positions and cursors are stable.
+func ranked(q Query, from, to time.Time) (string, []interface{}) {
+	// Balances carried into the ledger are not XP earned on the board.
+	join := "l.user_id = u.id AND l.currency = ? AND l.amount > 0 AND l.reason NOT IN ?"
+	args := []interface{}{wallet.Xp, []string{wallet.ReasonOpening, wallet.ReasonSignup}}
+	if !from.IsZero() {
+		join += " AND l.created_at >= ?"
+		args = append(args, from)
+	}
+	if !to.IsZero() {
+		join += " AND l.created_at < ?"
+		args = append(args, to)
+	}
+	var conds []string
+	if q.League != nil {
+		conds = append(conds, "u.league = ?")
+		args = append(args, *q.League)
+	}
+	if q.FriendsOf != 0 {
+		conds = append(conds, "(u.id = ? OR u.id IN (SELECT friend_id FROM friendships WHERE user_id = ?))")
+		args = append(args, q.FriendsOf, q.FriendsOf)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	sql := `SELECT s.*, ROW_NUMBER() OVER (ORDER BY s.score DESC, s.user_id) AS position FROM (
+		SELECT u.id AS user_id, u.name, u.avatar, u.profile_visibility AS visibility, u.league, COALESCE(SUM(l.amount), 0) AS score
+		FROM users u LEFT JOIN ledger_entries l ON ` + join + `
+		` + where + `
+		GROUP BY u.id
+	) s`
+	return sql, args
+}
+
+// Page returns up to limit entries after cursor and the cursor of the next
+// page, which is empty on the last page.
+func Page(db *gorm.DB, q Query, cursor string, limit int) ([]Entry, string, error) {
+	from, err := periodStart(q.Period, q.Now)
+	if err != nil {
+		return nil, "", err
+	}
+	sql, args := ranked(q, from, time.Time{})
+
+	after := 0
+	if cursor != "" {
+		if after, err = decodeCursor(cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var entries []Entry
+	err = db.Raw(`SELECT * FROM (`+sql+`) r WHERE r.position > ? ORDER BY r.position LIMIT ?`,
+		append(args, after, limit+1)...).Scan(&entries).Error
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(entries) > limit {
+		entries = entries[:limit]
+		next = encodeCursor(entries[len(entries)-1].Position)
+	}
+	return entries, next, nil
+}
+
+// Around returns the user's own entry with up to radius neighbors on each
+// side, or nothing when the user is not on the board.
+func Around(db *gorm.DB, q Query, userID uint, radius int) ([]Entry, error) {
+	from, err := periodStart(q.Period, q.Now)
+	if err != nil {
+		return nil, err
+	}
+	sql, args := ranked(q, from, time.Time{})
+
+	var entries []Entry
+	err = db.Raw(`WITH r AS (`+sql+`)
+		SELECT r.* FROM r, (SELECT position FROM r WHERE user_id = ?) me
+		WHERE r.position BETWEEN me.position - ? AND me.position + ?
+		ORDER BY r.position`,
+		append(args, userID, radius, radius)...).Scan(&entries).Error
+	return entries, err
+}
+
+// Cursors carry the last position seen. They are opaque to clients so the
+// format can change without breaking them.
+func encodeCursor(position int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("p:" + strconv.Itoa(position)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrBadCursor
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(string(raw), "p:"))
+	if err != nil || n < 0 || !strings.HasPrefix(string(raw), "p:") {
+		return 0, fmt.Errorf("%w: %q", ErrBadCursor, cursor)
+	}
+	return n, nil
+}
@@ -0,0 +1,126 @@
+package leaderboard
+
+import (
+	"TalUpBackend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var Leagues = []string{"Бронза", "Серебро", "Золото", "Сапфир", "Рубин", "Изумруд", "Алмаз"}
+
+// At the end of a week the best PromoteCount users of each league move up
+// and the worst DemoteCount move down. Users without XP that week rank last
+// and are never promoted.
+const (
+	PromoteCount = 7
+	DemoteCount  = 5
+)
+
+// lockKey serializes week closing between server instances.
+const lockKey = 7402119
+
+const (
+	OutcomePromoted = "promoted"
+	OutcomeDemoted  = "demoted"
+	OutcomeStayed   = "stayed"
+)
+
+// resultBatchSize is how many league_results rows are inserted at once.
+const resultBatchSize = 500
+
+func LeagueName(tier int) string {
+	if tier < 0 || tier >= len(Leagues) {
+		return ""
+	}
+	return Leagues[tier]
+}
+
+// CloseWeek settles every finished week that was not closed yet, oldest
+// first: the league moves are applied and written to league_results, and
+// the week is recorded in league_weeks. With no week closed before, only
+// the week before now is settled. It reports how many weeks it closed.
+// Calling it again within a week does nothing, so the server runs it on a
+// timer and the close-league-weeks command runs it on demand.
+func CloseWeek(db *gorm.DB, now time.Time) (int, error) {
+	current := WeekStart(now)
+	closed := 0
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+			return err
+		}
+		var last models.LeagueWeek
+		res := tx.Order("week_start DESC").Limit(1).Find(&last)
+		if res.Error != nil {
+			return res.Error
+		}
+		start := current.AddDate(0, 0, -7)
+		if res.RowsAffected > 0 {
+			start = WeekStart(last.WeekStart).AddDate(0, 0, 7)
+		}
+
+		for ; start.Before(current); start = start.AddDate(0, 0, 7) {
+			if err := closeWeek(tx, start, start.AddDate(0, 0, 7)); err != nil {
+				return err
+			}
+			closed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return closed, nil
+}
+
+// closeWeek ranks every league before moving anyone, so a user promoted or
+// demoted this week is not ranked again in their new league.
+func closeWeek(tx *gorm.DB, start, end time.Time) error {
+	standings := make([][]Entry, len(Leagues))
+	for tier := range Leagues {
+		sql, args := ranked(Query{League: &tier}, start, end)
+		if err := tx.Raw(`SELECT * FROM (`+sql+`) r ORDER BY r.position`, args...).
+			Scan(&standings[tier]).Error; err != nil {
+			return err
+		}
+	}
+	for tier, entries := range standings {
+		if err := settleLeague(tx, tier, start, entries); err != nil {
+			return err
+		}
+	}
+	return tx.Create(&models.LeagueWeek{WeekStart: start, ClosedAt: time.Now()}).Error
+}
+
+func settleLeague(tx *gorm.DB, tier int, start time.Time, entries []Entry) error {
+	results := make([]models.LeagueResult, 0, len(entries))
+	for i, e := range entries {
+		outcome, newTier := OutcomeStayed, tier
+		switch {
+		case i < PromoteCount && e.Score > 0 && tier < len(Leagues)-1:
+			outcome, newTier = OutcomePromoted, tier+1
+		case i >= len(entries)-DemoteCount && (i >= PromoteCount || e.Score == 0) && tier > 0:
+			outcome, newTier = OutcomeDemoted, tier-1
+		}
+
+		if newTier != tier {
+			if err := tx.Model(&models.User{}).Where("id = ?", e.UserID).
+				Updates(map[string]interface{}{"league": newTier, "version": gorm.Expr("version + 1")}).Error; err != nil {
+				return err
+			}
+		}
+		results = append(results, models.LeagueResult{
+			UserID:    e.UserID,
+			WeekStart: start,
+			League:    tier,
+			Position:  i + 1,
+			Score:     e.Score,
+			Outcome:   outcome,
+		})
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(results, resultBatchSize).Error
+}
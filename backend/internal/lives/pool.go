@@ -0,0 +1,87 @@
+package lives
+
+import (
+	"TalUpBackend/internal/config"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/wallet"
+	"time"
+)
+
+// Pool is the single source of truth for regular lives. A user below the cap
+// regains one life per interval counted from LifeRestoreAt, the start of the
+// current regeneration period; the anchor is zero while lives are full.
+// Nothing runs in the background: every read brings the row up to date
+// through Refill.
+type Pool struct {
+	max      int
+	interval time.Duration
+}
+
+func NewPool(cfg config.LivesConfig) *Pool {
+	return &Pool{max: cfg.Max, interval: cfg.RegenInterval}
+}
+
+func (p *Pool) Max() int {
+	return p.max
+}
+
+// Refill credits the lives regenerated up to now.
+func (p *Pool) Refill(user *models.User, ledger *wallet.Ledger, now time.Time) {
+	if user.Lives >= p.max {
+		user.LifeRestoreAt = time.Time{}
+		return
+	}
+	if user.LifeRestoreAt.IsZero() || user.LifeRestoreAt.After(now) {
+		user.LifeRestoreAt = now
+		return
+	}
+
+	periods := int(now.Sub(user.LifeRestoreAt) / p.interval)
+	if periods == 0 {
+		return
+	}
+	ledger.Add(wallet.Lives, min(periods, p.max-user.Lives), wallet.ReasonLifeRegen, wallet.NoSource)
+	if user.Lives >= p.max {
+		user.LifeRestoreAt = time.Time{}
+	} else {
+		user.LifeRestoreAt = user.LifeRestoreAt.Add(time.Duration(periods) * p.interval)
+	}
+}
+
+// Spend takes one life for a mistake, using bonus lives once regular ones
+// run out. It reports false when the user had none left.
+func (p *Pool) Spend(user *models.User, ledger *wallet.Ledger, src wallet.Source, now time.Time) bool {
+	p.Refill(user, ledger, now)
+	switch {
+	case user.Lives > 0:
+		ledger.Add(wallet.Lives, -1, wallet.ReasonMistake, src)
+		if user.LifeRestoreAt.IsZero() {
+			user.LifeRestoreAt = now
+		}
+	case user.BonusLives > 0:
+		ledger.Add(wallet.BonusLives, -1, wallet.ReasonMistake, src)
+	default:
+		return false
+	}
+	return true
+}
+
+// Grant adds regular lives, e.g. bought in the shop.
+func (p *Pool) Grant(user *models.User, ledger *wallet.Ledger, n int, reason string, src wallet.Source, now time.Time) {
+	p.Refill(user, ledger, now)
+	ledger.Add(wallet.Lives, n, reason, src)
+	if user.Lives >= p.max {
+		user.LifeRestoreAt = time.Time{}
+	}
+}
+
+// NextRefill returns when the next life arrives and when lives will be full
+// again; both are zero when lives are already full. Call Refill first.
+func (p *Pool) NextRefill(user models.User) (next, full time.Time) {
+	if user.Lives >= p.max || user.LifeRestoreAt.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	next = user.LifeRestoreAt.Add(p.interval)
+	full = user.LifeRestoreAt.Add(time.Duration(p.max-user.Lives) * p.interval)
+	return next, full
+}
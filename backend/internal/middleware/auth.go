@@ -1,56 +1,46 @@
 package middleware
 
 import (
+	"TalUpBackend/internal/auth"
 	"TalUpBackend/internal/db"
 	"TalUpBackend/internal/models"
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(tokens *auth.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "Token is missing"})
-			c.Abort()
+			abortUnauthorized(c, "Необходимо авторизоваться")
 			return
 		}
 
-		tokenString = strings.Replace(tokenString, "Bearer ", "", 1)
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte("secret_key"), nil
-		})
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid token"})
-			c.Abort()
+		tokenString = strings.TrimSpace(strings.TrimPrefix(tokenString, "Bearer "))
+		claims, err := tokens.ParseAccess(tokenString)
+		if err == auth.ErrTokenExpired {
+			abortUnauthorized(c, "Сессия истекла. Войдите заново")
 			return
 		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid token claims"})
-			c.Abort()
+		if err != nil {
+			abortUnauthorized(c, "Недействительный токен")
 			return
 		}
 
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid user ID in token"})
-			c.Abort()
+		if !tokens.SessionActive(claims.UserID, claims.SessionID) {
+			abortUnauthorized(c, "Сессия отозвана. Войдите заново")
 			return
 		}
 
 		var user models.User
-		if err := db.DB.First(&user, uint(userID)).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "User not found"})
-			c.Abort()
+		if err := db.DB.First(&user, claims.UserID).Error; err != nil {
+			abortUnauthorized(c, "Пользователь не найден")
 			return
 		}
 
-		c.Set("user", user)
+		c.Set(userKey, user)
+		c.Set(sessionKey, claims.SessionID)
 		c.Next()
 	}
 }
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"TalUpBackend/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	userKey    = "user"
+	sessionKey = "session_id"
+)
+
+func abortUnauthorized(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+}
+
+// CurrentUser returns the user loaded by AuthMiddleware. When it is missing
+// the request is answered with 401 and ok is false.
+func CurrentUser(c *gin.Context) (models.User, bool) {
+	value, exists := c.Get(userKey)
+	user, ok := value.(models.User)
+	if !exists || !ok {
+		abortUnauthorized(c, "Необходимо авторизоваться")
+		return models.User{}, false
+	}
+	return user, true
+}
+
+func CurrentUserID(c *gin.Context) (uint, bool) {
+	user, ok := CurrentUser(c)
+	return user.ID, ok
+}
+
+func CurrentSessionID(c *gin.Context) string {
+	return c.GetString(sessionKey)
+}
+
+// RequireRoles lets the request through only when the current user has one
+// of the given roles.
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, r := range roles {
+		allowed[r] = true
+	}
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			return
+		}
+		if !allowed[user.Role] {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// UserAchievement is an unlocked achievement. The primary key makes every
+// achievement, and so its reward, a one-time event per user.
+type UserAchievement struct {
+	UserID        uint      `json:"-" gorm:"primaryKey"`
+	AchievementID string    `json:"achievementId" gorm:"primaryKey"`
+	UnlockedAt    time.Time `json:"unlockedAt"`
+}
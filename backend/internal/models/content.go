@@ -0,0 +1,18 @@
+package models
+
+type Word struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Word        string `json:"word" gorm:"not null"`
+	Translation string `json:"translation"`
+}
+
+type Sentence struct {
+	ID                uint   `json:"id" gorm:"primaryKey"`
+	WordID            uint   `json:"word_id" gorm:"not null;index"`
+	Text              string `json:"text"`
+	MaskedSentence    string `json:"masked_sentence" gorm:"not null"`
+	CorrectAnswer     string `json:"correct_answer" gorm:"not null"`
+	Translation       string `json:"translation"`
+	TranslationTarget string `json:"translation_target"`
+	Difficulty        string `json:"difficulty" gorm:"not null"`
+}
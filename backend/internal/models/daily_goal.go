@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// DailyGoal is one entry of a user's goal history. A goal is in force from
+// EffectiveFrom, a local day, until the next entry, so past days are judged
+// against the goal the user had then.
+type DailyGoal struct {
+	ID            uint      `json:"-" gorm:"primaryKey"`
+	UserID        uint      `json:"-" gorm:"not null;uniqueIndex:idx_daily_goals_user_day"`
+	Metric        string    `json:"metric" gorm:"not null"`
+	Target        int       `json:"target" gorm:"not null"`
+	EffectiveFrom string    `json:"effectiveFrom" gorm:"not null;uniqueIndex:idx_daily_goals_user_day"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type IssuedTask struct {
+	ID             string `gorm:"primaryKey"`
+	UserID         uint   `gorm:"not null;index"`
+	WordID         uint   `gorm:"not null"`
+	Type           string `gorm:"not null"`
+	Difficulty     string
+	ExpectedAnswer string `gorm:"not null"`
+	AsrPassed      *bool
+	AsrScore       *float64
+	Correct        *bool
+	SessionID      *uint `gorm:"index"`
+	ConsumedAt     *time.Time
+	CreatedAt      time.Time
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// LeagueWeek marks a week whose league moves were applied.
+type LeagueWeek struct {
+	WeekStart time.Time `gorm:"primaryKey;type:date"`
+	ClosedAt  time.Time
+}
+
+type LeagueResult struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	UserID    uint      `json:"-" gorm:"not null;index"`
+	WeekStart time.Time `json:"weekStart" gorm:"type:date;not null"`
+	League    int       `json:"league"`
+	Position  int       `json:"position"`
+	Score     int       `json:"score"`
+	Outcome   string    `json:"outcome"`
+}
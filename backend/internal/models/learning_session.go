@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	SessionActive    = "active"
+	SessionFinished  = "finished"
+	SessionAbandoned = "abandoned"
+)
+
+// LearningSession is one lesson: the batch of tasks issued when it started
+// and, once finished, the summary of how it went.
+type LearningSession struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"-" gorm:"not null;index"`
+	Status          string     `json:"status" gorm:"not null;default:active"`
+	TaskCount       int        `json:"taskCount"`
+	Answered        int        `json:"answered"`
+	Correct         int        `json:"correct"`
+	Accuracy        float64    `json:"accuracy"`
+	XpEarned        int        `json:"xpEarned"`
+	WordsLearned    int        `json:"wordsLearned"`
+	BonusXp         int        `json:"bonusXp"`
+	BonusCoins      int        `json:"bonusCoins"`
+	DurationSeconds int        `json:"durationSeconds"`
+	StartedAt       time.Time  `json:"startedAt"`
+	FinishedAt      *time.Time `json:"finishedAt"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// LedgerEntry is one append-only change of a user's currency or XP balance.
+// The sum of Amount per currency equals the matching balance on User.
+type LedgerEntry struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"-" gorm:"not null;index"`
+	Currency   string    `json:"currency" gorm:"not null"`
+	Amount     int       `json:"amount" gorm:"not null"`
+	Reason     string    `json:"reason" gorm:"not null"`
+	SourceType string    `json:"sourceType,omitempty"`
+	SourceID   string    `json:"sourceId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RewardGrant records that a periodic reward was paid. The primary key lets
+// each reward be paid once per period, whichever request gets there first.
+type RewardGrant struct {
+	UserID     uint      `json:"-" gorm:"primaryKey"`
+	RewardType string    `json:"rewardType" gorm:"primaryKey"`
+	Period     string    `json:"period" gorm:"primaryKey"`
+	GrantedAt  time.Time `json:"grantedAt"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+const (
+	ItemLife         = "life"
+	ItemStreakFreeze = "streak_freeze"
+	ItemStreakRepair = "streak_repair"
+)
+
+// ShopItem is a catalog entry. MaxOwned caps how many the user may hold
+// and DailyLimit how many they may buy per local day; zero means no limit.
+type ShopItem struct {
+	ID          string `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"not null"`
+	Description string `json:"description"`
+	Price       int    `json:"price" gorm:"not null"`
+	MaxOwned    int    `json:"maxOwned"`
+	DailyLimit  int    `json:"dailyLimit"`
+	Active      bool   `json:"-" gorm:"not null;default:true"`
+}
+
+type UserItem struct {
+	UserID    uint      `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	ItemID    string    `json:"itemId" gorm:"primaryKey"`
+	Quantity  int       `json:"quantity" gorm:"not null;default:0"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Purchase is the ledger entry for one checkout; Day is the user's local
+// day, which daily limits are counted against.
+type Purchase struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"-" gorm:"not null;index"`
+	ItemID    string    `json:"itemId" gorm:"not null"`
+	Quantity  int       `json:"quantity" gorm:"not null"`
+	UnitPrice int       `json:"unitPrice" gorm:"not null"`
+	Total     int       `json:"total" gorm:"not null"`
+	Day       string    `json:"day" gorm:"not null"`
+	CreatedAt time.Time `json:"createdAt"`
+}
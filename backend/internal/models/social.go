@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Follow is one-directional and needs no consent.
+type Follow struct {
+	FollowerID uint `gorm:"primaryKey"`
+	FolloweeID uint `gorm:"primaryKey;index"`
+	CreatedAt  time.Time
+}
+
+// Friendship is stored twice, once from each side, so friends of a user
+// are found by user_id alone.
+type Friendship struct {
+	UserID    uint `gorm:"primaryKey"`
+	FriendID  uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+const (
+	FriendRequestPending  = "pending"
+	FriendRequestAccepted = "accepted"
+	FriendRequestDeclined = "declined"
+)
+
+type FriendRequest struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	FromID      uint       `json:"fromId" gorm:"not null;index"`
+	ToID        uint       `json:"toId" gorm:"not null;index"`
+	Status      string     `json:"status" gorm:"not null;default:pending"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RespondedAt *time.Time `json:"respondedAt"`
+}
+
+// Profile visibility: who may see a user's profile and progress.
+const (
+	VisibilityPublic  = "public"
+	VisibilityFriends = "friends"
+	VisibilityPrivate = "private"
+)
+
+func IsValidVisibility(v string) bool {
+	return v == VisibilityPublic || v == VisibilityFriends || v == VisibilityPrivate
+}
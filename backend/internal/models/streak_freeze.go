@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+const (
+	FreezeSourceFreeze = "freeze"
+	FreezeSourceRepair = "repair"
+)
+
+// StreakFreezeDay is a missed local day that was covered by a streak freeze
+// or a paid repair, so the streak survived it.
+type StreakFreezeDay struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	UserID    uint      `json:"-" gorm:"not null;uniqueIndex:idx_streak_freeze_days_user_day"`
+	Day       string    `json:"day" gorm:"not null;uniqueIndex:idx_streak_freeze_days_user_day"`
+	Source    string    `json:"source" gorm:"not null"`
+	CreatedAt time.Time `json:"createdAt"`
+}
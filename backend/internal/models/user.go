@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/lib/pq"
@@ -35,14 +36,86 @@ type User struct {
 	TreePhase           int            `json:"treePhase" gorm:"default:0"`
 	TreePhaseProgress   float64        `json:"treePhaseProgress" gorm:"default:0"`
 	TodayLearnedWords   int            `json:"todayLearnedWords" gorm:"default:0"`
+	DailyGoal           int            `json:"dailyGoal" gorm:"not null;default:5"`
+	DailyGoalMetric     string         `json:"dailyGoalMetric" gorm:"not null;default:words"`
 	Lives               int            `gorm:"default:5"`
-	LastLifeAdded       string         `gorm:"default:''"`
 	LifeRestoreAt       time.Time
 	BonusLives          int    `json:"bonusLives" gorm:"default:0"`
-	LastDailyGoalReward string `json:"lastDailyGoalReward"`
-	LastStreakReward    string `json:"lastStreakReward"`
 	TreeXp              int    `json:"treeXp" gorm:"default:0"`
 	Coins               int    `json:"coins" gorm:"default:0"`
+	Role                string `json:"role" gorm:"not null;default:learner"`
+	Timezone            string `json:"timezone" gorm:"not null;default:Asia/Almaty"`
+	BrokenStreak        int    `json:"brokenStreak" gorm:"default:0"`
+	BrokenStreakLastDay string `json:"-"`
+	BrokenStreakOn      string `json:"brokenStreakOn"`
+	League              int    `json:"league" gorm:"not null;default:0"`
+	ProfileVisibility   string `json:"profileVisibility" gorm:"not null;default:public"`
+	AllowFriendRequests bool   `json:"allowFriendRequests" gorm:"not null;default:true"`
+	Version             int    `json:"-" gorm:"not null;default:1"`
+}
+
+const DefaultTimezone = "Asia/Almaty"
+
+// Location is the user's time zone; streaks, daily goals and rewards are
+// all counted in the user's local days.
+func (user User) Location() *time.Location {
+	if loc, err := time.LoadLocation(user.Timezone); err == nil && user.Timezone != "" {
+		return loc
+	}
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (user User) LocalNow() time.Time {
+	return time.Now().In(user.Location())
+}
+
+func IsValidTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+const (
+	RoleLearner       = "learner"
+	RoleTeacher       = "teacher"
+	RoleContentEditor = "content_editor"
+	RoleAdmin         = "admin"
+)
+
+var Roles = []string{RoleLearner, RoleTeacher, RoleContentEditor, RoleAdmin}
+
+func IsValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrStaleUser means the row was saved by someone else after it was read.
+var ErrStaleUser = errors.New("user was modified concurrently")
+
+// SaveVersioned writes the whole row only if its version is unchanged since
+// it was read, and bumps the version. Writers that touch single columns
+// must bump the version as well.
+func (user *User) SaveVersioned(tx *gorm.DB) error {
+	prev := user.Version
+	user.Version++
+	res := tx.Model(user).Where("version = ?", prev).Select("*").Updates(user)
+	if res.Error == nil && res.RowsAffected == 0 {
+		res.Error = ErrStaleUser
+	}
+	if res.Error != nil {
+		user.Version = prev
+	}
+	return res.Error
 }
 
 func (user *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type UserWord struct {
 	ID                   uint   `gorm:"primaryKey"`
 	UserID               uint   `gorm:"not null"`
@@ -18,4 +20,11 @@ type UserWord struct {
 	CompletedTranslation bool
 	CompletedShuffle     bool
 	CompletedAsr         bool
+	Interval             int     `gorm:"default:0"`
+	Ease                 float64 `gorm:"default:2.5"`
+	Reps                 int     `gorm:"default:0"`
+	Lapses               int     `gorm:"default:0"`
+	DueAt                time.Time
+	LastReviewedAt       time.Time
+	CreatedAt            time.Time
 }
@@ -0,0 +1,132 @@
+package pronunciation
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	StatusMatch        = "match"
+	StatusSubstitution = "substitution"
+	StatusMiss         = "miss"
+	StatusExtra        = "extra"
+)
+
+// A heard word at least this close to the expected one counts as a match,
+// which tolerates small recognizer spelling slips.
+const matchSimilarity = 0.8
+
+type WordResult struct {
+	Expected   string  `json:"expected,omitempty"`
+	Heard      string  `json:"heard,omitempty"`
+	Status     string  `json:"status"`
+	Similarity float64 `json:"similarity"`
+}
+
+type Result struct {
+	Score float64      `json:"score"`
+	Words []WordResult `json:"words"`
+}
+
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+}
+
+// Similarity is 1 minus the rune-level edit distance normalized by the
+// longer word, so multi-byte Kazakh letters count as one character.
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+// Score aligns the heard words against the expected ones with a word-level
+// edit distance, where substituting one word for another costs how different
+// the two words are.
+func Score(expected, heard string) Result {
+	exp, got := Tokenize(expected), Tokenize(heard)
+	n, m := len(exp), len(got)
+
+	cost := make([][]float64, n+1)
+	for i := range cost {
+		cost[i] = make([]float64, m+1)
+		cost[i][0] = float64(i)
+	}
+	for j := 0; j <= m; j++ {
+		cost[0][j] = float64(j)
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			sub := cost[i-1][j-1] + 1 - Similarity(exp[i-1], got[j-1])
+			cost[i][j] = min(sub, cost[i-1][j]+1, cost[i][j-1]+1)
+		}
+	}
+
+	var words []WordResult
+	i, j := n, m
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && cost[i][j] == cost[i-1][j-1]+1-Similarity(exp[i-1], got[j-1]):
+			sim := Similarity(exp[i-1], got[j-1])
+			status := StatusSubstitution
+			if sim >= matchSimilarity {
+				status = StatusMatch
+			}
+			words = append(words, WordResult{Expected: exp[i-1], Heard: got[j-1], Status: status, Similarity: sim})
+			i, j = i-1, j-1
+		case i > 0 && cost[i][j] == cost[i-1][j]+1:
+			words = append(words, WordResult{Expected: exp[i-1], Status: StatusMiss})
+			i--
+		default:
+			words = append(words, WordResult{Heard: got[j-1], Status: StatusExtra})
+			j--
+		}
+	}
+	for l, r := 0, len(words)-1; l < r; l, r = l+1, r-1 {
+		words[l], words[r] = words[r], words[l]
+	}
+
+	var earned float64
+	extras := 0
+	for _, w := range words {
+		switch w.Status {
+		case StatusMatch:
+			earned++
+		case StatusSubstitution:
+			earned += w.Similarity
+		case StatusExtra:
+			extras++
+		}
+	}
+
+	score := 0.0
+	if total := n + extras; total > 0 && n > 0 {
+		score = earned / float64(total)
+	}
+	return Result{Score: score, Words: words}
+}
@@ -0,0 +1,101 @@
+package pronunciation
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"сәлем", "сәлем", 1},
+		{"", "", 1},
+		{"сәлем", "", 0},
+		// ә is two bytes but one letter.
+		{"әлем", "алем", 0.75},
+		{"кітап", "кітаптар", 0.625},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  string
+		heard     string
+		want      float64
+		wantWords []string
+	}{
+		{
+			name:      "exact ignoring case and punctuation",
+			expected:  "Сәлем, әлем!",
+			heard:     "сәлем әлем",
+			want:      1,
+			wantWords: []string{StatusMatch, StatusMatch},
+		},
+		{
+			name:      "small slip still matches",
+			expected:  "мен оқимын",
+			heard:     "мен оқимен",
+			want:      1,
+			wantWords: []string{StatusMatch, StatusMatch},
+		},
+		{
+			name:      "different word is a partial substitution",
+			expected:  "кітап",
+			heard:     "кітаптар",
+			want:      0.625,
+			wantWords: []string{StatusSubstitution},
+		},
+		{
+			name:      "missing word",
+			expected:  "мен кітап оқимын",
+			heard:     "мен оқимын",
+			want:      2.0 / 3,
+			wantWords: []string{StatusMatch, StatusMiss, StatusMatch},
+		},
+		{
+			name:      "extra word lowers the score",
+			expected:  "кітап оқы",
+			heard:     "кітап оқы енді",
+			want:      2.0 / 3,
+			wantWords: []string{StatusMatch, StatusMatch, StatusExtra},
+		},
+		{
+			name:      "nothing heard",
+			expected:  "сәлем әлем",
+			heard:     "",
+			want:      0,
+			wantWords: []string{StatusMiss, StatusMiss},
+		},
+		{
+			name:      "nothing expected",
+			expected:  "",
+			heard:     "сәлем",
+			want:      0,
+			wantWords: []string{StatusExtra},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.expected, tt.heard)
+			if math.Abs(got.Score-tt.want) > 1e-9 {
+				t.Errorf("score = %v, want %v", got.Score, tt.want)
+			}
+			if len(got.Words) != len(tt.wantWords) {
+				t.Fatalf("words = %+v, want statuses %v", got.Words, tt.wantWords)
+			}
+			for i, w := range got.Words {
+				if w.Status != tt.wantWords[i] {
+					t.Errorf("word %d = %+v, want status %s", i, w, tt.wantWords[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package rewards
+
+import (
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/wallet"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	TypeDailyGoal = "daily_goal"
+	TypeStreak    = "streak"
+)
+
+type Amount struct {
+	Currency string
+	Value    int
+}
+
+// Reward is what one grant pays.
+type Reward struct {
+	Type    string
+	Reason  string
+	Amounts []Amount
+}
+
+const (
+	StreakBonusLives    = 2
+	StreakTreeXp        = 5
+	DailyGoalBonusLives = 2
+)
+
+// Streak is paid on the days the streak engine emits a streak_reward event.
+var Streak = Reward{
+	Type:   TypeStreak,
+	Reason: wallet.ReasonStreakReward,
+	Amounts: []Amount{
+		{wallet.BonusLives, StreakBonusLives},
+		{wallet.TreeXp, StreakTreeXp},
+	},
+}
+
+// DailyGoal is paid when the day's goal of learned words is reached. Bigger
+// goals earn more tree XP.
+func DailyGoal(goal int) Reward {
+	return Reward{
+		Type:   TypeDailyGoal,
+		Reason: wallet.ReasonDailyGoal,
+		Amounts: []Amount{
+			{wallet.BonusLives, DailyGoalBonusLives},
+			{wallet.TreeXp, goal},
+		},
+	}
+}
+
+// Grant pays r to the user for period unless it was paid for that period
+// already, and reports whether it paid. It must run in the transaction that
+// saves the user and flushes ledger, so the grant row and the balances are
+// committed or rolled back together.
+func Grant(tx *gorm.DB, user *models.User, ledger *wallet.Ledger, r Reward, period string) (bool, error) {
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RewardGrant{
+		UserID:     user.ID,
+		RewardType: r.Type,
+		Period:     period,
+		GrantedAt:  time.Now(),
+	})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+
+	src := wallet.Source{Type: "reward", ID: r.Type + ":" + period}
+	for _, a := range r.Amounts {
+		ledger.Add(a.Currency, a.Value, r.Reason, src)
+	}
+	return true, nil
+}
@@ -0,0 +1,195 @@
+package shop
+
+import (
+	"TalUpBackend/internal/achievements"
+	"TalUpBackend/internal/lives"
+	"TalUpBackend/internal/models"
+	"TalUpBackend/internal/streak"
+	"TalUpBackend/internal/wallet"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnknownItem     = errors.New("shop: unknown item")
+	ErrInvalidQuantity = errors.New("shop: invalid quantity")
+	ErrNotEnoughCoins  = errors.New("shop: not enough coins")
+	ErrStockLimit      = errors.New("shop: stock limit reached")
+	ErrDailyLimit      = errors.New("shop: daily limit reached")
+	ErrNotEnoughItems  = errors.New("shop: not enough items")
+)
+
+// MaxQuantity bounds a single purchase, which keeps the price total far
+// from overflowing.
+const MaxQuantity = 100
+
+type Result struct {
+	User     models.User
+	Purchase models.Purchase
+	// Owned is how many of the item the user holds after the purchase.
+	Owned int
+	// RepairedDays lists the days a streak repair covered.
+	RepairedDays []string
+	// Achievements lists what the purchase unlocked, e.g. streak
+	// milestones reached by a repair.
+	Achievements []achievements.Achievement
+}
+
+func Items(db *gorm.DB) ([]models.ShopItem, error) {
+	var items []models.ShopItem
+	err := db.Where("active").Order("price, id").Find(&items).Error
+	return items, err
+}
+
+// Quantity returns how many of an inventory item the user holds.
+func Quantity(db *gorm.DB, userID uint, itemID string) (int, error) {
+	var row models.UserItem
+	err := db.Where("user_id = ? AND item_id = ?", userID, itemID).Limit(1).Find(&row).Error
+	return row.Quantity, err
+}
+
+// Consume removes n of an inventory item if the user holds that many and
+// returns ErrNotEnoughItems otherwise.
+func Consume(db *gorm.DB, userID uint, itemID string, n int) error {
+	res := db.Model(&models.UserItem{}).
+		Where("user_id = ? AND item_id = ? AND quantity >= ?", userID, itemID, n).
+		Updates(map[string]interface{}{"quantity": gorm.Expr("quantity - ?", n), "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotEnoughItems
+	}
+	return nil
+}
+
+// Purchase buys qty of an item in one transaction: the user row is locked,
+// limits are checked, coins are debited, the item takes effect and the
+// purchase is written to the ledger. Nothing is changed on error.
+func Purchase(db *gorm.DB, pool *lives.Pool, userID uint, itemID string, qty int) (Result, error) {
+	var res Result
+	if qty <= 0 || qty > MaxQuantity {
+		return res, ErrInvalidQuantity
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var item models.ShopItem
+		if err := tx.Where("id = ? AND active", itemID).First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUnknownItem
+			}
+			return err
+		}
+
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return err
+		}
+		now := user.LocalNow()
+		day := now.Format("2006-01-02")
+		ledger := wallet.New(&user)
+		pool.Refill(&user, ledger, now)
+
+		have, err := owned(tx, user, item.ID)
+		if err != nil {
+			return err
+		}
+		limit := item.MaxOwned
+		if item.ID == models.ItemLife {
+			limit = pool.Max()
+		}
+		if limit > 0 && have+qty > limit {
+			return ErrStockLimit
+		}
+
+		if item.DailyLimit > 0 {
+			var bought int64
+			if err := tx.Model(&models.Purchase{}).
+				Where("user_id = ? AND item_id = ? AND day = ?", user.ID, item.ID, day).
+				Select("COALESCE(SUM(quantity), 0)").Scan(&bought).Error; err != nil {
+				return err
+			}
+			if int(bought)+qty > item.DailyLimit {
+				return ErrDailyLimit
+			}
+		}
+
+		total := item.Price * qty
+		if user.Coins < total {
+			return ErrNotEnoughCoins
+		}
+		src := wallet.Source{Type: "shop_item", ID: item.ID}
+		ledger.Add(wallet.Coins, -total, wallet.ReasonPurchase, src)
+
+		switch item.ID {
+		case models.ItemLife:
+			pool.Grant(&user, ledger, qty, wallet.ReasonPurchase, src, now)
+		case models.ItemStreakFreeze:
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "item_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"quantity": gorm.Expr("user_items.quantity + ?", qty), "updated_at": time.Now()}),
+			}).Create(&models.UserItem{UserID: user.ID, ItemID: item.ID, Quantity: qty}).Error; err != nil {
+				return err
+			}
+		case models.ItemStreakRepair:
+			if qty != 1 {
+				return ErrInvalidQuantity
+			}
+			days, err := streak.Repair(&user, now)
+			if err != nil {
+				return err
+			}
+			for _, d := range days {
+				fd := models.StreakFreezeDay{UserID: user.ID, Day: d, Source: models.FreezeSourceRepair}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&fd).Error; err != nil {
+					return err
+				}
+			}
+			res.RepairedDays = days
+			if res.Achievements, err = achievements.Evaluate(tx, &user, ledger, achievements.EventStreakExtended); err != nil {
+				return err
+			}
+		default:
+			return ErrUnknownItem
+		}
+
+		if err := user.SaveVersioned(tx); err != nil {
+			return err
+		}
+		if err := ledger.Flush(tx); err != nil {
+			return err
+		}
+
+		purchase := models.Purchase{
+			UserID:    user.ID,
+			ItemID:    item.ID,
+			Quantity:  qty,
+			UnitPrice: item.Price,
+			Total:     total,
+			Day:       day,
+		}
+		if err := tx.Create(&purchase).Error; err != nil {
+			return err
+		}
+
+		res.User = user
+		res.Purchase = purchase
+		res.Owned, err = owned(tx, user, item.ID)
+		return err
+	})
+	return res, err
+}
+
+func owned(tx *gorm.DB, user models.User, itemID string) (int, error) {
+	switch itemID {
+	case models.ItemLife:
+		return user.Lives, nil
+	case models.ItemStreakRepair:
+		return 0, nil
+	default:
+		return Quantity(tx, user.ID, itemID)
+	}
+}
@@ -0,0 +1,216 @@
+package social
+
+import (
+	"TalUpBackend/internal/models"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSelf             = errors.New("social: cannot target yourself")
+	ErrUserNotFound     = errors.New("social: user not found")
+	ErrAlreadyFriends   = errors.New("social: already friends")
+	ErrAlreadyRequested = errors.New("social: request already sent")
+	ErrRequestsClosed   = errors.New("social: user does not accept friend requests")
+	ErrRequestNotFound  = errors.New("social: friend request not found")
+)
+
+func Follow(db *gorm.DB, followerID, followeeID uint) error {
+	if followerID == followeeID {
+		return ErrSelf
+	}
+	if err := exists(db, followeeID); err != nil {
+		return err
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.Follow{FollowerID: followerID, FolloweeID: followeeID}).Error
+}
+
+func Unfollow(db *gorm.DB, followerID, followeeID uint) error {
+	return db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Delete(&models.Follow{}).Error
+}
+
+// SendRequest asks toID for friendship. If toID has already asked fromID,
+// that request is accepted instead and the returned request is the accepted
+// one.
+func SendRequest(db *gorm.DB, fromID, toID uint) (models.FriendRequest, error) {
+	var req models.FriendRequest
+	if fromID == toID {
+		return req, ErrSelf
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPair(tx, fromID, toID); err != nil {
+			return err
+		}
+
+		var target models.User
+		if err := tx.Select("id", "allow_friend_requests").First(&target, toID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		friends, err := AreFriends(tx, fromID, toID)
+		if err != nil {
+			return err
+		}
+		if friends {
+			return ErrAlreadyFriends
+		}
+
+		var open []models.FriendRequest
+		if err := tx.Where("status = ? AND ((from_id = ? AND to_id = ?) OR (from_id = ? AND to_id = ?))",
+			models.FriendRequestPending, fromID, toID, toID, fromID).Find(&open).Error; err != nil {
+			return err
+		}
+		for _, r := range open {
+			if r.FromID == fromID {
+				return ErrAlreadyRequested
+			}
+		}
+		if len(open) > 0 {
+			req = open[0]
+			return accept(tx, &req)
+		}
+
+		if !target.AllowFriendRequests {
+			return ErrRequestsClosed
+		}
+		req = models.FriendRequest{FromID: fromID, ToID: toID, Status: models.FriendRequestPending}
+		return tx.Create(&req).Error
+	})
+	return req, err
+}
+
+// Respond accepts or declines a pending request addressed to userID.
+func Respond(db *gorm.DB, userID, requestID uint, ok bool) (models.FriendRequest, error) {
+	var req models.FriendRequest
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND to_id = ? AND status = ?", requestID, userID, models.FriendRequestPending).
+			First(&req).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRequestNotFound
+			}
+			return err
+		}
+		if err := lockPair(tx, req.FromID, req.ToID); err != nil {
+			return err
+		}
+		if ok {
+			return accept(tx, &req)
+		}
+		now := time.Now()
+		req.Status = models.FriendRequestDeclined
+		req.RespondedAt = &now
+		return tx.Save(&req).Error
+	})
+	return req, err
+}
+
+func Unfriend(db *gorm.DB, userID, friendID uint) error {
+	return db.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+		userID, friendID, friendID, userID).Delete(&models.Friendship{}).Error
+}
+
+func AreFriends(db *gorm.DB, a, b uint) (bool, error) {
+	var n int64
+	err := db.Model(&models.Friendship{}).Where("user_id = ? AND friend_id = ?", a, b).Count(&n).Error
+	return n > 0, err
+}
+
+// CanView reports whether viewerID may see owner's profile and progress.
+func CanView(db *gorm.DB, viewerID uint, owner models.User) (bool, error) {
+	friends := map[uint]bool{}
+	if owner.ProfileVisibility == models.VisibilityFriends && viewerID != owner.ID {
+		ok, err := AreFriends(db, viewerID, owner.ID)
+		if err != nil {
+			return false, err
+		}
+		friends[owner.ID] = ok
+	}
+	return Visible(viewerID, owner.ID, owner.ProfileVisibility, friends), nil
+}
+
+// Visible is the CanView rule for a viewer whose friends are already known,
+// for checking many owners at once.
+func Visible(viewerID, ownerID uint, visibility string, friends map[uint]bool) bool {
+	if viewerID == ownerID {
+		return true
+	}
+	switch visibility {
+	case models.VisibilityPrivate:
+		return false
+	case models.VisibilityFriends:
+		return friends[ownerID]
+	}
+	return true
+}
+
+// FriendIDs returns the ids of the user's friends.
+func FriendIDs(db *gorm.DB, userID uint) (map[uint]bool, error) {
+	var ids []uint
+	if err := db.Model(&models.Friendship{}).Where("user_id = ?", userID).Pluck("friend_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		out[id] = true
+	}
+	return out, nil
+}
+
+// Friends returns the user's friends ordered by name.
+func Friends(db *gorm.DB, userID uint) ([]models.User, error) {
+	var users []models.User
+	err := db.Where("id IN (?)", db.Model(&models.Friendship{}).Select("friend_id").Where("user_id = ?", userID)).
+		Order("name, id").Find(&users).Error
+	return users, err
+}
+
+// Search finds users whose username starts with query, ignoring case.
+func Search(db *gorm.DB, query string, limit int) ([]models.User, error) {
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(query)) + "%"
+	var users []models.User
+	err := db.Where("lower(username) LIKE ?", pattern).Order("username").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+func accept(tx *gorm.DB, req *models.FriendRequest) error {
+	now := time.Now()
+	req.Status = models.FriendRequestAccepted
+	req.RespondedAt = &now
+	if err := tx.Save(req).Error; err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&[]models.Friendship{
+		{UserID: req.FromID, FriendID: req.ToID, CreatedAt: now},
+		{UserID: req.ToID, FriendID: req.FromID, CreatedAt: now},
+	}).Error
+}
+
+// lockPair serializes changes between two users, so requests sent to each
+// other at the same time do not both stay pending.
+func lockPair(tx *gorm.DB, a, b uint) error {
+	if a > b {
+		a, b = b, a
+	}
+	return tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", int32(a), int32(b)).Error
+}
+
+func exists(db *gorm.DB, userID uint) error {
+	var n int64
+	if err := db.Model(&models.User{}).Where("id = ?", userID).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
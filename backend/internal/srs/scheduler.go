@@ -0,0 +1,62 @@
+package srs
+
+import (
+	"TalUpBackend/internal/models"
+	"math"
+	"time"
+)
+
+const (
+	DefaultEase    = 2.5
+	MinEase        = 1.3
+	RelearnDelay   = 10 * time.Minute
+	DailyNewBudget = 10
+)
+
+// Review applies an SM-2 step to the word. A correct answer only moves the
+// schedule forward when the word is actually due, so answering several task
+// types for the same word in one lesson does not inflate its interval.
+func Review(uw *models.UserWord, success bool, now time.Time) {
+	if uw.Ease < MinEase {
+		uw.Ease = DefaultEase
+	}
+
+	if success {
+		if !IsDue(*uw, now) {
+			return
+		}
+		uw.Reps++
+		switch uw.Reps {
+		case 1:
+			uw.Interval = 1
+		case 2:
+			uw.Interval = 6
+		default:
+			uw.Interval = int(math.Round(float64(uw.Interval) * uw.Ease))
+		}
+		uw.DueAt = now.AddDate(0, 0, uw.Interval)
+		uw.LastReviewedAt = now
+		return
+	}
+
+	if uw.Interval > 0 {
+		uw.Lapses++
+		uw.Ease -= 0.2
+		if uw.Ease < MinEase {
+			uw.Ease = MinEase
+		}
+	}
+	uw.Reps = 0
+	uw.Interval = 0
+	uw.DueAt = now.Add(RelearnDelay)
+	uw.LastReviewedAt = now
+}
+
+func IsDue(uw models.UserWord, now time.Time) bool {
+	return uw.DueAt.IsZero() || !uw.DueAt.After(now)
+}
+
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,119 @@
+package srs
+
+import (
+	"TalUpBackend/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReview(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	due := now.Add(-time.Hour)
+	later := now.AddDate(0, 0, 3)
+
+	tests := []struct {
+		name         string
+		word         models.UserWord
+		success      bool
+		wantReps     int
+		wantInterval int
+		wantEase     float64
+		wantLapses   int
+		wantDueAt    time.Time
+	}{
+		{
+			name:         "first success",
+			word:         models.UserWord{},
+			success:      true,
+			wantReps:     1,
+			wantInterval: 1,
+			wantEase:     DefaultEase,
+			wantDueAt:    now.AddDate(0, 0, 1),
+		},
+		{
+			name:         "second success",
+			word:         models.UserWord{Reps: 1, Interval: 1, Ease: DefaultEase, DueAt: due},
+			success:      true,
+			wantReps:     2,
+			wantInterval: 6,
+			wantEase:     DefaultEase,
+			wantDueAt:    now.AddDate(0, 0, 6),
+		},
+		{
+			name:         "later success multiplies by ease",
+			word:         models.UserWord{Reps: 2, Interval: 6, Ease: 2.2, DueAt: due},
+			success:      true,
+			wantReps:     3,
+			wantInterval: 13,
+			wantEase:     2.2,
+			wantDueAt:    now.AddDate(0, 0, 13),
+		},
+		{
+			name:         "success before due changes nothing",
+			word:         models.UserWord{Reps: 2, Interval: 6, Ease: DefaultEase, DueAt: later},
+			success:      true,
+			wantReps:     2,
+			wantInterval: 6,
+			wantEase:     DefaultEase,
+			wantDueAt:    later,
+		},
+		{
+			name:       "lapse lowers ease and relearns",
+			word:       models.UserWord{Reps: 3, Interval: 15, Ease: DefaultEase, DueAt: due},
+			success:    false,
+			wantEase:   DefaultEase - 0.2,
+			wantLapses: 1,
+			wantDueAt:  now.Add(RelearnDelay),
+		},
+		{
+			name:       "lapse keeps ease at the floor",
+			word:       models.UserWord{Reps: 1, Interval: 1, Ease: MinEase + 0.1, Lapses: 2, DueAt: due},
+			success:    false,
+			wantEase:   MinEase,
+			wantLapses: 3,
+			wantDueAt:  now.Add(RelearnDelay),
+		},
+		{
+			name:      "mistake on a new word is not a lapse",
+			word:      models.UserWord{},
+			success:   false,
+			wantEase:  DefaultEase,
+			wantDueAt: now.Add(RelearnDelay),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uw := tt.word
+			Review(&uw, tt.success, now)
+			if uw.Reps != tt.wantReps || uw.Interval != tt.wantInterval || uw.Lapses != tt.wantLapses {
+				t.Errorf("reps, interval, lapses = %d, %d, %d, want %d, %d, %d",
+					uw.Reps, uw.Interval, uw.Lapses, tt.wantReps, tt.wantInterval, tt.wantLapses)
+			}
+			if math.Abs(uw.Ease-tt.wantEase) > 1e-9 {
+				t.Errorf("ease = %v, want %v", uw.Ease, tt.wantEase)
+			}
+			if !uw.DueAt.Equal(tt.wantDueAt) {
+				t.Errorf("due at = %v, want %v", uw.DueAt, tt.wantDueAt)
+			}
+		})
+	}
+}
+
+func TestIsDue(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		dueAt time.Time
+		want  bool
+	}{
+		{time.Time{}, true},
+		{now, true},
+		{now.Add(-time.Minute), true},
+		{now.Add(time.Minute), false},
+	}
+	for _, tt := range tests {
+		if got := IsDue(models.UserWord{DueAt: tt.dueAt}, now); got != tt.want {
+			t.Errorf("IsDue(due at %v) = %v, want %v", tt.dueAt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package streak
+
+import (
+	"TalUpBackend/internal/models"
+	"errors"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Streak rewards are due on every odd streak day starting from the third.
+// The engine only reports them; the rewards package pays them.
+const RewardMinStreak = 3
+
+// Inactivity beyond the grace period costs tree XP per missed day, unless
+// streak freezes covered the gap.
+const (
+	InactivityGraceDays = 2
+	InactivityPenaltyXp = 2
+)
+
+// A broken streak can be repaired for this many days after the break.
+const RepairWindowDays = 2
+
+var (
+	ErrNothingToRepair = errors.New("streak: no broken streak to repair")
+	ErrRepairExpired   = errors.New("streak: repair window has passed")
+)
+
+type EventKind string
+
+const (
+	EventDayStarted        EventKind = "day_started"
+	EventStreakExtended    EventKind = "streak_extended"
+	EventStreakReset       EventKind = "streak_reset"
+	EventStreakFrozen      EventKind = "streak_frozen"
+	EventStreakReward      EventKind = "streak_reward"
+	EventInactivityPenalty EventKind = "inactivity_penalty"
+)
+
+// Event describes one change Rollover made. Value carries the new streak
+// length for streak events, the freezes spent for a frozen streak and the
+// tree XP lost for the penalty. Days lists the local days a freeze covered.
+type Event struct {
+	Kind  EventKind `json:"kind"`
+	Value int       `json:"value"`
+	Days  []string  `json:"days,omitempty"`
+}
+
+var weekdays = []string{"ПН", "ВТ", "СР", "ЧТ", "ПТ", "СБ", "ВС"}
+
+// Weekday returns the short Russian label the client shows for a day.
+func Weekday(t time.Time) string {
+	return weekdays[(int(t.Weekday())+6)%7]
+}
+
+// Rollover moves the user into the local day of now. It is a no-op when the
+// user was already seen that day, so handlers may call it on every request.
+// now must already be in the user's time zone. freezes is how many streak
+// freezes the user owns; a streak_frozen event reports how many to spend.
+func Rollover(user *models.User, freezes int, now time.Time) []Event {
+	today := now.Format(dateLayout)
+	if user.LastLogin == today {
+		return nil
+	}
+
+	events := []Event{{Kind: EventDayStarted}}
+	user.TodayLearnedWords = 0
+
+	daysInactive := 0
+	last, err := time.ParseInLocation(dateLayout, user.LastLogin, now.Location())
+	if err == nil {
+		daysInactive = daysBetween(last, now)
+	}
+
+	missed := daysInactive - 1
+	frozen := false
+	switch {
+	case err == nil && daysInactive == 1:
+		user.StreakCount++
+		events = append(events, Event{Kind: EventStreakExtended, Value: user.StreakCount})
+	case err == nil && missed > 0 && user.StreakCount > 0 && freezes >= missed:
+		events = append(events, Event{Kind: EventStreakFrozen, Value: missed, Days: daysAfter(last, missed)})
+		frozen = true
+		user.StreakCount++
+		events = append(events, Event{Kind: EventStreakExtended, Value: user.StreakCount})
+	default:
+		if err == nil && user.StreakCount > 0 {
+			user.BrokenStreak = user.StreakCount
+			user.BrokenStreakLastDay = user.LastLogin
+			user.BrokenStreakOn = today
+		}
+		user.StreakCount = 1
+		user.StreakDays = []string{}
+		events = append(events, Event{Kind: EventStreakReset, Value: user.StreakCount})
+	}
+	user.StreakDays = addWeekday(user.StreakDays, Weekday(now))
+	user.LastLogin = today
+
+	// Days covered by freezes count as active, so they cost no tree XP.
+	if daysInactive > InactivityGraceDays && !frozen {
+		penalty := (daysInactive - InactivityGraceDays) * InactivityPenaltyXp
+		if penalty > user.TreeXp {
+			penalty = user.TreeXp
+		}
+		user.TreeXp -= penalty
+		events = append(events, Event{Kind: EventInactivityPenalty, Value: penalty})
+	}
+
+	if user.StreakCount >= RewardMinStreak && user.StreakCount%2 == 1 {
+		events = append(events, Event{Kind: EventStreakReward, Value: user.StreakCount})
+	}
+
+	RecalculateTreePhase(user)
+	return events
+}
+
+// Repair restores the streak that was broken within the last
+// RepairWindowDays and returns the missed days it covers.
+func Repair(user *models.User, now time.Time) ([]string, error) {
+	if user.BrokenStreak == 0 {
+		return nil, ErrNothingToRepair
+	}
+	brokenOn, err := time.ParseInLocation(dateLayout, user.BrokenStreakOn, now.Location())
+	if err != nil || daysBetween(brokenOn, now) > RepairWindowDays {
+		return nil, ErrRepairExpired
+	}
+
+	var days []string
+	if last, err := time.ParseInLocation(dateLayout, user.BrokenStreakLastDay, now.Location()); err == nil {
+		days = daysAfter(last, daysBetween(last, brokenOn)-1)
+	}
+
+	user.StreakCount += user.BrokenStreak
+	user.BrokenStreak = 0
+	user.BrokenStreakLastDay = ""
+	user.BrokenStreakOn = ""
+	return days, nil
+}
+
+// daysAfter lists the n local days following from.
+func daysAfter(from time.Time, n int) []string {
+	days := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		days = append(days, from.AddDate(0, 0, i).Format(dateLayout))
+	}
+	return days
+}
+
+// daysBetween counts calendar days from a to b, ignoring DST shifts.
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	from := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	to := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// addWeekday adds day to the list and returns it de-duplicated in
+// Monday-first order.
+func addWeekday(days []string, day string) []string {
+	seen := map[string]bool{day: true}
+	for _, d := range days {
+		seen[d] = true
+	}
+	out := []string{}
+	for _, d := range weekdays {
+		if seen[d] {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+var treeThresholds = []int{0, 30, 80, 160, 280, 450}
+
+func RecalculateTreePhase(user *models.User) {
+	xp := user.TreeXp
+	phase := 0
+
+	for i := len(treeThresholds) - 1; i >= 0; i-- {
+		if xp >= treeThresholds[i] {
+			phase = i
+			break
+		}
+	}
+
+	user.TreePhase = phase
+	if phase+1 < len(treeThresholds) {
+		user.TreePhaseProgress = float64(xp-treeThresholds[phase]) / float64(treeThresholds[phase+1]-treeThresholds[phase]) * 100
+	} else {
+		user.TreePhaseProgress = 100
+	}
+}
@@ -0,0 +1,347 @@
+package streak
+
+import (
+	"TalUpBackend/internal/models"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func kinds(events []Event) []EventKind {
+	out := []EventKind{}
+	for _, e := range events {
+		out = append(out, e.Kind)
+	}
+	return out
+}
+
+func find(events []Event, kind EventKind) (Event, bool) {
+	for _, e := range events {
+		if e.Kind == kind {
+			return e, true
+		}
+	}
+	return Event{}, false
+}
+
+func TestRollover(t *testing.T) {
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+	berlin := mustLoad(t, "Europe/Berlin")
+	noon := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		user    models.User
+		freezes int
+		now     time.Time
+
+		wantKinds   []EventKind
+		wantStreak  int
+		wantTreeXp  int
+		wantFrozen  []string
+		wantPenalty int
+		wantBroken  int
+	}{
+		{
+			name:       "same day",
+			user:       models.User{LastLogin: "2026-10-18", StreakCount: 4, TreeXp: 50, TodayLearnedWords: 3},
+			now:        noon,
+			wantKinds:  []EventKind{},
+			wantStreak: 4,
+			wantTreeXp: 50,
+		},
+		{
+			name:       "next day",
+			user:       models.User{LastLogin: "2026-10-17", StreakCount: 1, TreeXp: 50},
+			now:        noon,
+			wantKinds:  []EventKind{EventDayStarted, EventStreakExtended},
+			wantStreak: 2,
+			wantTreeXp: 50,
+		},
+		{
+			name:       "gap covered by freezes",
+			user:       models.User{LastLogin: "2026-10-15", StreakCount: 5, TreeXp: 50},
+			freezes:    2,
+			now:        noon,
+			wantKinds:  []EventKind{EventDayStarted, EventStreakFrozen, EventStreakExtended},
+			wantStreak: 6,
+			// Frozen days count as active, so the gap past the grace
+			// period costs no tree XP.
+			wantTreeXp: 50,
+			wantFrozen: []string{"2026-10-16", "2026-10-17"},
+		},
+		{
+			name:       "gap without enough freezes",
+			user:       models.User{LastLogin: "2026-10-16", StreakCount: 5, TreeXp: 50},
+			now:        noon,
+			wantKinds:  []EventKind{EventDayStarted, EventStreakReset},
+			wantStreak: 1,
+			wantTreeXp: 50,
+			wantBroken: 5,
+		},
+		{
+			name:        "inactivity penalty",
+			user:        models.User{LastLogin: "2026-10-14", StreakCount: 2, TreeXp: 50},
+			now:         noon,
+			wantKinds:   []EventKind{EventDayStarted, EventStreakReset, EventInactivityPenalty},
+			wantStreak:  1,
+			wantTreeXp:  46,
+			wantPenalty: 4,
+			wantBroken:  2,
+		},
+		{
+			name:        "inactivity penalty capped at tree XP",
+			user:        models.User{LastLogin: "2026-09-18", TreeXp: 5},
+			now:         noon,
+			wantKinds:   []EventKind{EventDayStarted, EventStreakReset, EventInactivityPenalty},
+			wantStreak:  1,
+			wantTreeXp:  0,
+			wantPenalty: 5,
+		},
+		{
+			name:       "first login",
+			user:       models.User{TreeXp: 50},
+			now:        noon,
+			wantKinds:  []EventKind{EventDayStarted, EventStreakReset},
+			wantStreak: 1,
+			wantTreeXp: 50,
+		},
+		{
+			name:       "no reward on day two",
+			user:       models.User{LastLogin: "2026-10-17", StreakCount: 1},
+			now:        noon,
+			wantKinds:  []EventKind{EventDayStarted, EventStreakExtended},
+			wantStreak: 2,
+		},
+		{
+			name:       "reward on day three",
+			user:       models.User{LastLogin: "2026-10-17", StreakCount: 2},
+			now:        noon,
+			wantKinds:  []EventKind{EventDayStarted, EventStreakExtended, EventStreakReward},
+			wantStreak: 3,
+		},
+		{
+			name:       "no reward on even day",
+			user:       models.User{LastLogin: "2026-10-17", StreakCount: 3},
+			now:        noon,
+			wantKinds:  []EventKind{EventDayStarted, EventStreakExtended},
+			wantStreak: 4,
+		},
+		{
+			name:       "reward on day five",
+			user:       models.User{LastLogin: "2026-10-17", StreakCount: 4},
+			now:        noon,
+			wantKinds:  []EventKind{EventDayStarted, EventStreakExtended, EventStreakReward},
+			wantStreak: 5,
+		},
+		{
+			name:       "local day ahead of UTC",
+			user:       models.User{LastLogin: "2026-10-17", StreakCount: 1},
+			now:        time.Date(2026, 10, 17, 21, 30, 0, 0, time.UTC).In(almaty),
+			wantKinds:  []EventKind{EventDayStarted, EventStreakExtended},
+			wantStreak: 2,
+		},
+		{
+			name:       "local day behind UTC",
+			user:       models.User{LastLogin: "2026-10-17", StreakCount: 1},
+			now:        time.Date(2026, 10, 17, 18, 30, 0, 0, time.UTC).In(almaty),
+			wantKinds:  []EventKind{},
+			wantStreak: 1,
+		},
+		{
+			name:       "spring forward",
+			user:       models.User{LastLogin: "2026-03-28", StreakCount: 1},
+			now:        time.Date(2026, 3, 29, 23, 30, 0, 0, berlin),
+			wantKinds:  []EventKind{EventDayStarted, EventStreakExtended},
+			wantStreak: 2,
+		},
+		{
+			name:       "fall back",
+			user:       models.User{LastLogin: "2026-10-24", StreakCount: 1},
+			now:        time.Date(2026, 10, 25, 0, 30, 0, 0, berlin),
+			wantKinds:  []EventKind{EventDayStarted, EventStreakExtended},
+			wantStreak: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := tt.user
+			events := Rollover(&user, tt.freezes, tt.now)
+
+			if got := kinds(events); !reflect.DeepEqual(got, tt.wantKinds) {
+				t.Fatalf("events = %v, want %v", got, tt.wantKinds)
+			}
+			if user.StreakCount != tt.wantStreak {
+				t.Errorf("streak = %d, want %d", user.StreakCount, tt.wantStreak)
+			}
+			if user.TreeXp != tt.wantTreeXp {
+				t.Errorf("tree XP = %d, want %d", user.TreeXp, tt.wantTreeXp)
+			}
+			if user.BrokenStreak != tt.wantBroken {
+				t.Errorf("broken streak = %d, want %d", user.BrokenStreak, tt.wantBroken)
+			}
+			if e, ok := find(events, EventStreakFrozen); ok {
+				if !reflect.DeepEqual(e.Days, tt.wantFrozen) || e.Value != len(tt.wantFrozen) {
+					t.Errorf("frozen = %d %v, want %v", e.Value, e.Days, tt.wantFrozen)
+				}
+			}
+			if e, ok := find(events, EventInactivityPenalty); ok && e.Value != tt.wantPenalty {
+				t.Errorf("penalty = %d, want %d", e.Value, tt.wantPenalty)
+			}
+			if e, ok := find(events, EventStreakReward); ok && e.Value != user.StreakCount {
+				t.Errorf("reward for day %d, streak is %d", e.Value, user.StreakCount)
+			}
+
+			if len(events) == 0 {
+				if user.LastLogin != tt.user.LastLogin || user.TodayLearnedWords != tt.user.TodayLearnedWords {
+					t.Errorf("no-op rollover changed the user: %+v", user)
+				}
+				return
+			}
+			if want := tt.now.Format(dateLayout); user.LastLogin != want {
+				t.Errorf("last login = %q, want %q", user.LastLogin, want)
+			}
+			if user.TodayLearnedWords != 0 {
+				t.Errorf("today's words = %d, want 0", user.TodayLearnedWords)
+			}
+			if want := Weekday(tt.now); !contains(user.StreakDays, want) {
+				t.Errorf("streak days = %v, want %s", user.StreakDays, want)
+			}
+		})
+	}
+}
+
+func contains(days []string, day string) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRolloverIsIdempotent(t *testing.T) {
+	user := models.User{LastLogin: "2026-10-17", StreakCount: 2}
+	now := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+
+	Rollover(&user, 0, now)
+	after := user
+	if events := Rollover(&user, 0, now.Add(10*time.Hour)); events != nil {
+		t.Fatalf("second rollover on the same day returned %v", events)
+	}
+	if !reflect.DeepEqual(user, after) {
+		t.Errorf("second rollover changed the user: %+v", user)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	broken := func(on string) models.User {
+		return models.User{
+			StreakCount:         1,
+			BrokenStreak:        6,
+			BrokenStreakLastDay: "2026-10-14",
+			BrokenStreakOn:      on,
+		}
+	}
+	noon := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		user       models.User
+		wantErr    error
+		wantDays   []string
+		wantStreak int
+	}{
+		{
+			name:       "on the day of the break",
+			user:       broken("2026-10-18"),
+			wantDays:   []string{"2026-10-15", "2026-10-16", "2026-10-17"},
+			wantStreak: 7,
+		},
+		{
+			name:       "last day of the window",
+			user:       broken("2026-10-16"),
+			wantDays:   []string{"2026-10-15"},
+			wantStreak: 7,
+		},
+		{
+			name:       "window passed",
+			user:       broken("2026-10-15"),
+			wantErr:    ErrRepairExpired,
+			wantStreak: 1,
+		},
+		{
+			name:       "nothing broken",
+			user:       models.User{StreakCount: 3},
+			wantErr:    ErrNothingToRepair,
+			wantStreak: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := tt.user
+			days, err := Repair(&user, noon)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(days, tt.wantDays) {
+				t.Errorf("days = %v, want %v", days, tt.wantDays)
+			}
+			if user.StreakCount != tt.wantStreak {
+				t.Errorf("streak = %d, want %d", user.StreakCount, tt.wantStreak)
+			}
+			if err == nil && (user.BrokenStreak != 0 || user.BrokenStreakOn != "") {
+				t.Errorf("repair left the broken streak behind: %+v", user)
+			}
+			if err != nil && !reflect.DeepEqual(user, tt.user) {
+				t.Errorf("failed repair changed the user: %+v", user)
+			}
+		})
+	}
+}
+
+func TestWeekday(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+	for i, want := range []string{"ПН", "ВТ", "СР", "ЧТ", "ПТ", "СБ", "ВС"} {
+		if got := Weekday(monday.AddDate(0, 0, i)); got != want {
+			t.Errorf("Weekday(%s) = %s, want %s", monday.AddDate(0, 0, i).Format(dateLayout), got, want)
+		}
+	}
+}
+
+func TestRecalculateTreePhase(t *testing.T) {
+	tests := []struct {
+		xp           int
+		wantPhase    int
+		wantProgress float64
+	}{
+		{xp: 0, wantPhase: 0, wantProgress: 0},
+		{xp: 15, wantPhase: 0, wantProgress: 50},
+		{xp: 30, wantPhase: 1, wantProgress: 0},
+		{xp: 79, wantPhase: 1, wantProgress: 98},
+		{xp: 280, wantPhase: 4, wantProgress: 0},
+		{xp: 450, wantPhase: 5, wantProgress: 100},
+		{xp: 9000, wantPhase: 5, wantProgress: 100},
+	}
+	for _, tt := range tests {
+		user := models.User{TreeXp: tt.xp}
+		RecalculateTreePhase(&user)
+		if user.TreePhase != tt.wantPhase || user.TreePhaseProgress != tt.wantProgress {
+			t.Errorf("tree XP %d: phase %d at %.1f%%, want %d at %.1f%%",
+				tt.xp, user.TreePhase, user.TreePhaseProgress, tt.wantPhase, tt.wantProgress)
+		}
+	}
+}
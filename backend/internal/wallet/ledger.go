@@ -0,0 +1,184 @@
+package wallet
+
+import (
+	"TalUpBackend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	Coins      = "coins"
+	Xp         = "xp"
+	TreeXp     = "tree_xp"
+	Lives      = "lives"
+	BonusLives = "bonus_lives"
+)
+
+var Currencies = []string{Coins, Xp, TreeXp, Lives, BonusLives}
+
+const (
+	ReasonOpening      = "opening_balance"
+	ReasonSignup       = "signup"
+	ReasonTaskReward   = "task_reward"
+	ReasonMistake      = "mistake"
+	ReasonDailyGoal    = "daily_goal"
+	ReasonLevelUp      = "level_up"
+	ReasonWordLearned  = "word_learned"
+	ReasonStreakReward = "streak_reward"
+	ReasonInactivity   = "inactivity_penalty"
+	ReasonLifeRegen    = "life_regen"
+	ReasonPurchase     = "purchase"
+	ReasonSessionBonus = "session_bonus"
+	ReasonAchievement  = "achievement"
+)
+
+// Source points at what caused a change, e.g. an issued task or a shop item.
+type Source struct {
+	Type string
+	ID   string
+}
+
+var NoSource = Source{}
+
+// Ledger buffers the balance changes made to one user until Flush writes
+// them next to the user row.
+type Ledger struct {
+	user    *models.User
+	entries []models.LedgerEntry
+}
+
+func New(user *models.User) *Ledger {
+	return &Ledger{user: user}
+}
+
+// Add changes a balance by amount without letting it drop below zero and
+// returns the amount actually applied.
+func (l *Ledger) Add(currency string, amount int, reason string, src Source) int {
+	field := balance(l.user, currency)
+	if *field+amount < 0 {
+		amount = -*field
+	}
+	*field += amount
+	l.Record(currency, amount, reason, src)
+	return amount
+}
+
+// Record notes a change that was already applied to the user.
+func (l *Ledger) Record(currency string, amount int, reason string, src Source) {
+	if amount == 0 {
+		return
+	}
+	l.entries = append(l.entries, models.LedgerEntry{
+		UserID:     l.user.ID,
+		Currency:   currency,
+		Amount:     amount,
+		Reason:     reason,
+		SourceType: src.Type,
+		SourceID:   src.ID,
+	})
+}
+
+// Pending sums the positive changes of currency not yet flushed, for
+// callers that need totals including the current request.
+func (l *Ledger) Pending(currency string) int {
+	sum := 0
+	for _, e := range l.entries {
+		if e.Currency == currency && e.Amount > 0 {
+			sum += e.Amount
+		}
+	}
+	return sum
+}
+
+func (l *Ledger) Flush(db *gorm.DB) error {
+	if len(l.entries) == 0 {
+		return nil
+	}
+	err := db.Create(&l.entries).Error
+	l.entries = nil
+	return err
+}
+
+func balance(user *models.User, currency string) *int {
+	switch currency {
+	case Coins:
+		return &user.Coins
+	case Xp:
+		return &user.Xp
+	case TreeXp:
+		return &user.TreeXp
+	case Lives:
+		return &user.Lives
+	case BonusLives:
+		return &user.BonusLives
+	}
+	panic("wallet: unknown currency " + currency)
+}
+
+func IsCurrency(name string) bool {
+	for _, cur := range Currencies {
+		if cur == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Opening records the user's current balances, for accounts that start with
+// a non-zero balance.
+func Opening(db *gorm.DB, user *models.User, reason string) error {
+	l := New(user)
+	for _, cur := range Currencies {
+		l.Record(cur, *balance(user, cur), reason, NoSource)
+	}
+	return l.Flush(db)
+}
+
+// History returns the newest entries first. beforeID, the id of the oldest
+// entry already seen, pages through older ones. Entries flushed together
+// share created_at, so the page boundary compares (created_at, id).
+func History(db *gorm.DB, userID uint, currency string, beforeID uint, limit int) ([]models.LedgerEntry, error) {
+	q := db.Where("user_id = ?", userID)
+	if currency != "" {
+		q = q.Where("currency = ?", currency)
+	}
+	if beforeID != 0 {
+		q = q.Where("(created_at, id) < (SELECT created_at, id FROM ledger_entries WHERE id = ? AND user_id = ?)", beforeID, userID)
+	}
+	var entries []models.LedgerEntry
+	err := q.Order("created_at DESC, id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+type Mismatch struct {
+	Currency string `json:"currency"`
+	Balance  int    `json:"balance"`
+	Ledger   int    `json:"ledger"`
+}
+
+// Reconcile compares the user's balances with the ledger sums and returns
+// every currency where they disagree.
+func Reconcile(db *gorm.DB, user models.User) ([]Mismatch, error) {
+	var rows []struct {
+		Currency string
+		Total    int
+	}
+	if err := db.Model(&models.LedgerEntry{}).
+		Select("currency, COALESCE(SUM(amount), 0) AS total").
+		Where("user_id = ?", user.ID).
+		Group("currency").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	sums := make(map[string]int, len(rows))
+	for _, r := range rows {
+		sums[r.Currency] = r.Total
+	}
+
+	var out []Mismatch
+	for _, cur := range Currencies {
+		if have := *balance(&user, cur); have != sums[cur] {
+			out = append(out, Mismatch{Currency: cur, Balance: have, Ledger: sums[cur]})
+		}
+	}
+	return out, nil
+}
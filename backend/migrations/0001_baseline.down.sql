@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS issued_tasks;
+DROP TABLE IF EXISTS user_words;
+DROP TABLE IF EXISTS users;
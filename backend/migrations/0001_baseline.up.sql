@@ -0,0 +1,94 @@
+CREATE TABLE IF NOT EXISTS users (
+    id                     bigserial PRIMARY KEY,
+    username               text NOT NULL UNIQUE,
+    email                  text NOT NULL UNIQUE,
+    password_hash          text,
+    name                   text,
+    gender                 text,
+    language               text,
+    birthdate              text,
+    current_level          text,
+    aim_level              text,
+    study_time             text,
+    goals                  text[],
+    last_active_date       text,
+    checked_days           text[],
+    xp                     bigint DEFAULT 0,
+    max_xp                 bigint DEFAULT 20,
+    level                  bigint DEFAULT 1,
+    "time"                 text,
+    avatar                 text,
+    streak_days            text[],
+    last_login             text,
+    streak_count           bigint,
+    learned_words          bigint,
+    learning_words         bigint,
+    tree_phase             bigint DEFAULT 0,
+    tree_phase_progress    numeric DEFAULT 0,
+    today_learned_words    bigint DEFAULT 0,
+    lives                  bigint DEFAULT 5,
+    last_life_added        text DEFAULT '',
+    life_restore_at        timestamptz,
+    bonus_lives            bigint DEFAULT 0,
+    last_daily_goal_reward text,
+    last_streak_reward     text,
+    tree_xp                bigint DEFAULT 0,
+    coins                  bigint DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS user_words (
+    id                    bigserial PRIMARY KEY,
+    user_id               bigint NOT NULL,
+    word_id               bigint NOT NULL,
+    repeats               bigint DEFAULT 0,
+    mistakes              bigint DEFAULT 0,
+    status                text DEFAULT 'new',
+    last_seen             text,
+    coefficient           numeric DEFAULT 0,
+    task_types_passed     text DEFAULT '',
+    repeats_standard      bigint DEFAULT 0,
+    repeats_translation   bigint DEFAULT 0,
+    repeats_shuffle       bigint DEFAULT 0,
+    repeats_asr           bigint DEFAULT 0,
+    completed_standard    boolean,
+    completed_translation boolean,
+    completed_shuffle     boolean,
+    completed_asr         boolean,
+    "interval"            bigint DEFAULT 0,
+    ease                  numeric DEFAULT 2.5,
+    reps                  bigint DEFAULT 0,
+    lapses                bigint DEFAULT 0,
+    due_at                timestamptz,
+    last_reviewed_at      timestamptz,
+    created_at            timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_words_user_word ON user_words (user_id, word_id);
+
+CREATE TABLE IF NOT EXISTS issued_tasks (
+    id              text PRIMARY KEY,
+    user_id         bigint NOT NULL,
+    word_id         bigint NOT NULL,
+    type            text NOT NULL,
+    difficulty      text,
+    expected_answer text NOT NULL,
+    asr_passed      boolean,
+    consumed_at     timestamptz,
+    created_at      timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_issued_tasks_user_id ON issued_tasks (user_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    family_id  text NOT NULL,
+    token_hash text NOT NULL,
+    expires_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
@@ -0,0 +1,3 @@
+ALTER TABLE user_words DROP CONSTRAINT IF EXISTS fk_user_words_word;
+DROP TABLE IF EXISTS sentences;
+DROP TABLE IF EXISTS words;
@@ -0,0 +1,23 @@
+CREATE TABLE words (
+    id          bigserial PRIMARY KEY,
+    word        text NOT NULL,
+    translation text
+);
+
+CREATE TABLE sentences (
+    id                 bigserial PRIMARY KEY,
+    word_id            bigint NOT NULL REFERENCES words (id) ON DELETE CASCADE,
+    text               text,
+    masked_sentence    text NOT NULL CHECK (masked_sentence LIKE '%<mask>%'),
+    correct_answer     text NOT NULL,
+    translation        text,
+    translation_target text,
+    difficulty         text NOT NULL CHECK (difficulty IN ('A', 'B', 'C'))
+);
+
+CREATE INDEX idx_sentences_word_id ON sentences (word_id);
+
+-- Existing progress rows predate the words table; the importer validates
+-- this constraint once the content is loaded.
+ALTER TABLE user_words
+    ADD CONSTRAINT fk_user_words_word FOREIGN KEY (word_id) REFERENCES words (id) NOT VALID;
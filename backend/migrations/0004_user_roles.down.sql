@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN is_admin boolean NOT NULL DEFAULT false;
+
+UPDATE users SET is_admin = true WHERE role = 'admin';
+
+ALTER TABLE users DROP COLUMN role;
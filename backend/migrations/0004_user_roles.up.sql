@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN role text NOT NULL DEFAULT 'learner'
+    CHECK (role IN ('learner', 'teacher', 'content_editor', 'admin'));
+
+UPDATE users SET role = 'admin' WHERE is_admin;
+
+ALTER TABLE users DROP COLUMN is_admin;
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN timezone text NOT NULL DEFAULT 'Asia/Almaty';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS streak_freeze_days;
+
+ALTER TABLE users DROP COLUMN IF EXISTS broken_streak_on;
+ALTER TABLE users DROP COLUMN IF EXISTS broken_streak_last_day;
+ALTER TABLE users DROP COLUMN IF EXISTS broken_streak;
+ALTER TABLE users DROP COLUMN IF EXISTS streak_freezes;
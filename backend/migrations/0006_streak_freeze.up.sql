@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN streak_freezes bigint DEFAULT 0;
+ALTER TABLE users ADD COLUMN broken_streak bigint DEFAULT 0;
+ALTER TABLE users ADD COLUMN broken_streak_last_day text;
+ALTER TABLE users ADD COLUMN broken_streak_on text;
+
+CREATE TABLE streak_freeze_days (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    day        text NOT NULL,
+    source     text NOT NULL,
+    created_at timestamptz
+);
+
+CREATE UNIQUE INDEX idx_streak_freeze_days_user_day ON streak_freeze_days (user_id, day);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS purchases;
+
+ALTER TABLE users ADD COLUMN streak_freezes bigint DEFAULT 0;
+
+UPDATE users SET streak_freezes = ui.quantity
+FROM user_items ui
+WHERE ui.user_id = users.id AND ui.item_id = 'streak_freeze';
+
+DROP TABLE IF EXISTS user_items;
+DROP TABLE IF EXISTS shop_items;
@@ -0,0 +1,41 @@
+CREATE TABLE shop_items (
+    id          text PRIMARY KEY,
+    name        text NOT NULL,
+    description text,
+    price       bigint NOT NULL CHECK (price >= 0),
+    max_owned   bigint NOT NULL DEFAULT 0,
+    daily_limit bigint NOT NULL DEFAULT 0,
+    active      boolean NOT NULL DEFAULT true
+);
+
+INSERT INTO shop_items (id, name, description, price, max_owned, daily_limit) VALUES
+    ('life', 'Жизнь', 'Восстанавливает одну жизнь', 5, 5, 0),
+    ('streak_freeze', 'Заморозка стрика', 'Сохраняет стрик за пропущенный день', 10, 2, 0),
+    ('streak_repair', 'Восстановление стрика', 'Возвращает недавно прерванный стрик', 20, 0, 1);
+
+CREATE TABLE user_items (
+    user_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    item_id    text NOT NULL REFERENCES shop_items (id),
+    quantity   bigint NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at timestamptz,
+    PRIMARY KEY (user_id, item_id)
+);
+
+INSERT INTO user_items (user_id, item_id, quantity, updated_at)
+SELECT id, 'streak_freeze', streak_freezes, now() FROM users WHERE streak_freezes > 0;
+
+ALTER TABLE users DROP COLUMN streak_freezes;
+
+CREATE TABLE purchases (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    item_id    text NOT NULL REFERENCES shop_items (id),
+    quantity   bigint NOT NULL CHECK (quantity > 0),
+    unit_price bigint NOT NULL,
+    total      bigint NOT NULL,
+    day        text NOT NULL,
+    created_at timestamptz
+);
+
+CREATE INDEX idx_purchases_user_id ON purchases (user_id);
+CREATE INDEX idx_purchases_user_item_day ON purchases (user_id, item_id, day);
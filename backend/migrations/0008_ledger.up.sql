@@ -0,0 +1,27 @@
+CREATE TABLE ledger_entries (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    currency    text NOT NULL CHECK (currency IN ('coins', 'xp', 'tree_xp', 'lives', 'bonus_lives')),
+    amount      bigint NOT NULL,
+    reason      text NOT NULL,
+    source_type text,
+    source_id   text,
+    created_at  timestamptz
+);
+
+CREATE INDEX idx_ledger_entries_user_id ON ledger_entries (user_id, created_at);
+
+-- The ledger starts from the balances users hold today.
+INSERT INTO ledger_entries (user_id, currency, amount, reason, created_at)
+SELECT id, b.currency, b.amount, 'opening_balance', now()
+FROM users,
+LATERAL (VALUES
+    ('coins', COALESCE(coins, 0)),
+    ('xp', COALESCE(xp, 0)),
+    ('tree_xp', COALESCE(tree_xp, 0)),
+    ('lives', COALESCE(lives, 0)),
+    ('bonus_lives', COALESCE(bonus_lives, 0))
+) AS b (currency, amount)
+WHERE b.amount <> 0;
+
+CREATE RULE ledger_entries_no_update AS ON UPDATE TO ledger_entries DO INSTEAD NOTHING;
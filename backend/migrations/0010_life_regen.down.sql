@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN last_life_added text DEFAULT '';
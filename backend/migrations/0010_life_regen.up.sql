@@ -0,0 +1,18 @@
+-- Lives now regenerate from life_restore_at alone. Rows that only had the
+-- old last_life_added stamp start their regeneration period from it.
+--
+-- The old code wrote that stamp in the API server's local time without a
+-- zone. migrations.Up puts the UTC offset of the process running the
+-- migration in talup.local_utc_offset, so run it on the API server's host
+-- or with its TZ.
+--
+-- Users with full lives are backfilled too rather than assuming a maximum
+-- here: the lives pool drops the anchor of anyone at or above the
+-- configured maximum on their next request.
+UPDATE users
+SET life_restore_at = to_timestamp(last_life_added, 'YYYY-MM-DD HH24:MI:SS')::timestamp
+    AT TIME ZONE current_setting('talup.local_utc_offset')::interval
+WHERE (life_restore_at IS NULL OR life_restore_at < '0002-01-01')
+  AND last_life_added <> '';
+
+ALTER TABLE users DROP COLUMN last_life_added;
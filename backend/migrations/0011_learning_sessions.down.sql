@@ -0,0 +1,4 @@
+ALTER TABLE issued_tasks DROP COLUMN IF EXISTS session_id;
+ALTER TABLE issued_tasks DROP COLUMN IF EXISTS correct;
+
+DROP TABLE IF EXISTS learning_sessions;
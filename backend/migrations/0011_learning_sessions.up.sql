@@ -0,0 +1,23 @@
+CREATE TABLE learning_sessions (
+    id               bigserial PRIMARY KEY,
+    user_id          bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status           text NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'finished', 'abandoned')),
+    task_count       bigint NOT NULL DEFAULT 0,
+    answered         bigint NOT NULL DEFAULT 0,
+    correct          bigint NOT NULL DEFAULT 0,
+    accuracy         numeric NOT NULL DEFAULT 0,
+    xp_earned        bigint NOT NULL DEFAULT 0,
+    words_learned    bigint NOT NULL DEFAULT 0,
+    bonus_xp         bigint NOT NULL DEFAULT 0,
+    bonus_coins      bigint NOT NULL DEFAULT 0,
+    duration_seconds bigint NOT NULL DEFAULT 0,
+    started_at       timestamptz NOT NULL,
+    finished_at      timestamptz
+);
+
+CREATE INDEX idx_learning_sessions_user_id ON learning_sessions (user_id);
+
+ALTER TABLE issued_tasks ADD COLUMN correct boolean;
+ALTER TABLE issued_tasks ADD COLUMN session_id bigint REFERENCES learning_sessions (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_issued_tasks_session_id ON issued_tasks (session_id);
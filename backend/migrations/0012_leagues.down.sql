@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_ledger_entries_xp;
+
+DROP TABLE IF EXISTS league_results;
+DROP TABLE IF EXISTS league_weeks;
+
+ALTER TABLE users DROP COLUMN IF EXISTS league;
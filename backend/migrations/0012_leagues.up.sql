@@ -0,0 +1,21 @@
+ALTER TABLE users ADD COLUMN league bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE league_weeks (
+    week_start date PRIMARY KEY,
+    closed_at  timestamptz NOT NULL
+);
+
+CREATE TABLE league_results (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    week_start date NOT NULL,
+    league     bigint NOT NULL,
+    position   bigint NOT NULL,
+    score      bigint NOT NULL,
+    outcome    text NOT NULL CHECK (outcome IN ('promoted', 'demoted', 'stayed'))
+);
+
+CREATE UNIQUE INDEX idx_league_results_user_week ON league_results (user_id, week_start);
+
+-- Boards sum earned XP per period.
+CREATE INDEX idx_ledger_entries_xp ON ledger_entries (created_at, user_id) WHERE currency = 'xp' AND amount > 0;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_users_username_lower;
+
+DROP TABLE IF EXISTS friend_requests;
+DROP TABLE IF EXISTS friendships;
+DROP TABLE IF EXISTS follows;
+
+ALTER TABLE users DROP COLUMN IF EXISTS allow_friend_requests;
+ALTER TABLE users DROP COLUMN IF EXISTS profile_visibility;
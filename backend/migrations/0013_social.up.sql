@@ -0,0 +1,39 @@
+ALTER TABLE users ADD COLUMN profile_visibility text NOT NULL DEFAULT 'public'
+    CHECK (profile_visibility IN ('public', 'friends', 'private'));
+ALTER TABLE users ADD COLUMN allow_friend_requests boolean NOT NULL DEFAULT true;
+
+CREATE TABLE follows (
+    follower_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    followee_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at  timestamptz,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX idx_follows_followee_id ON follows (followee_id);
+
+CREATE TABLE friendships (
+    user_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    friend_id  bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamptz,
+    PRIMARY KEY (user_id, friend_id),
+    CHECK (user_id <> friend_id)
+);
+
+CREATE TABLE friend_requests (
+    id           bigserial PRIMARY KEY,
+    from_id      bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    to_id        bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status       text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at   timestamptz,
+    responded_at timestamptz,
+    CHECK (from_id <> to_id)
+);
+
+CREATE INDEX idx_friend_requests_from_id ON friend_requests (from_id);
+CREATE INDEX idx_friend_requests_to_id ON friend_requests (to_id);
+-- At most one open request per pair and direction.
+CREATE UNIQUE INDEX idx_friend_requests_pending ON friend_requests (from_id, to_id) WHERE status = 'pending';
+
+-- Username search is case-insensitive by prefix.
+CREATE INDEX idx_users_username_lower ON users (lower(username) text_pattern_ops);
@@ -0,0 +1,3 @@
+ALTER TABLE issued_tasks DROP COLUMN IF EXISTS asr_score;
+
+DROP TABLE IF EXISTS user_achievements;
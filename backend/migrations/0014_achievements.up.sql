@@ -0,0 +1,8 @@
+CREATE TABLE user_achievements (
+    user_id        bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    achievement_id text NOT NULL,
+    unlocked_at    timestamptz NOT NULL,
+    PRIMARY KEY (user_id, achievement_id)
+);
+
+ALTER TABLE issued_tasks ADD COLUMN asr_score numeric;
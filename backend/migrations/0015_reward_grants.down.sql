@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN last_daily_goal_reward text;
+ALTER TABLE users ADD COLUMN last_streak_reward text;
+
+UPDATE users u SET last_daily_goal_reward = g.period
+FROM (SELECT user_id, MAX(period) AS period FROM reward_grants WHERE reward_type = 'daily_goal' GROUP BY user_id) g
+WHERE g.user_id = u.id;
+UPDATE users u SET last_streak_reward = g.period
+FROM (SELECT user_id, MAX(period) AS period FROM reward_grants WHERE reward_type = 'streak' GROUP BY user_id) g
+WHERE g.user_id = u.id;
+
+DROP TABLE IF EXISTS reward_grants;
@@ -0,0 +1,17 @@
+CREATE TABLE reward_grants (
+    user_id     bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    reward_type text NOT NULL,
+    period      text NOT NULL,
+    granted_at  timestamptz NOT NULL,
+    PRIMARY KEY (user_id, reward_type, period)
+);
+
+-- The last-reward markers become the first grants, so rewards already paid
+-- today are not paid again.
+INSERT INTO reward_grants (user_id, reward_type, period, granted_at)
+SELECT id, 'daily_goal', last_daily_goal_reward, now() FROM users WHERE last_daily_goal_reward <> '';
+INSERT INTO reward_grants (user_id, reward_type, period, granted_at)
+SELECT id, 'streak', last_streak_reward, now() FROM users WHERE last_streak_reward <> '';
+
+ALTER TABLE users DROP COLUMN last_daily_goal_reward;
+ALTER TABLE users DROP COLUMN last_streak_reward;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS daily_goals;
+
+ALTER TABLE users DROP COLUMN IF EXISTS daily_goal_metric;
+ALTER TABLE users DROP COLUMN IF EXISTS daily_goal;
@@ -0,0 +1,26 @@
+ALTER TABLE users ADD COLUMN daily_goal bigint NOT NULL DEFAULT 5 CHECK (daily_goal > 0);
+ALTER TABLE users ADD COLUMN daily_goal_metric text NOT NULL DEFAULT 'words'
+    CHECK (daily_goal_metric IN ('words', 'xp'));
+
+-- The goal used to follow the study time picked during onboarding.
+UPDATE users SET daily_goal = CASE time
+    WHEN 'one' THEN 3
+    WHEN 'three' THEN 8
+    WHEN 'more' THEN 12
+    ELSE 5
+END;
+
+CREATE TABLE daily_goals (
+    id             bigserial PRIMARY KEY,
+    user_id        bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    metric         text NOT NULL CHECK (metric IN ('words', 'xp')),
+    target         bigint NOT NULL CHECK (target > 0),
+    effective_from text NOT NULL,
+    created_at     timestamptz
+);
+
+CREATE UNIQUE INDEX idx_daily_goals_user_day ON daily_goals (user_id, effective_from);
+
+-- Existing users have had their current goal all along.
+INSERT INTO daily_goals (user_id, metric, target, effective_from, created_at)
+SELECT id, daily_goal_metric, daily_goal, '0001-01-01', now() FROM users;
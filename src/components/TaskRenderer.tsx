@@ -1,6 +1,6 @@
 import { Audio } from 'expo-av';
 import * as FileSystem from "expo-file-system";
-import React, { useEffect, useMemo, useRef, useState } from "react";
+import React, { useEffect, useRef, useState } from "react";
 import {
   Animated,
   Image,
@@ -12,18 +12,17 @@ import {
 } from "react-native";
 import { COLORS } from "../constants/colors";
 import { scaleFont, scaleSize } from "../constants/dimensions";
+import { apiPost } from "../utils/api";
 import { apiUpload } from '../utils/wav';
 import Button from "./Button";
 
 interface Task {
   word_id: number;
   sentence: string;
-  correctAnswer: string;
   translation: string;
   difficulty: string;
   options: string[];
   id?: string;
-  originalSentence?: string;
   translationTarget?: string;
   type?: string;
   text?: string;
@@ -42,13 +41,15 @@ const stopIcon = require("../../assets/stop.png");
 
 interface Props {
   task: Task;
-  onAnswer: (correct: boolean) => void;
+  onAnswer: (correct: boolean, answer: string | string[]) => void;
   currentIndex: number;
   total: number;
 }
 
 const TaskRenderer: React.FC<Props> = ({ task, onAnswer, currentIndex, total }) => {
   const [selected, setSelected] = useState<string | null>(null);
+  // The server grades the answer and only then reveals the expected one.
+  const [verdict, setVerdict] = useState<{ correct: boolean; answer: string } | null>(null);
   const [answered, setAnswered] = useState(false);
   const [constructed, setConstructed] = useState<string[]>([]);
   const opacity = useRef(new Animated.Value(0)).current;
@@ -132,7 +133,7 @@ const TaskRenderer: React.FC<Props> = ({ task, onAnswer, currentIndex, total })
         name: "audio.m4a",
         type: "audio/mp4",
       } as any);
-      formData.append("expected", task.text?.toLowerCase()?.trim() ?? "");
+      formData.append("task_id", task.id ?? "");
 
       const { response, data } = await apiUpload("/api/asr-submit", formData);
       console.log(`[ASR] Server response: ${response?.status} | Correct: ${data?.correct}`);
@@ -149,13 +150,22 @@ const TaskRenderer: React.FC<Props> = ({ task, onAnswer, currentIndex, total })
   const normalize = (str: string) =>
     str.replace(/[.,!?]/g, "").replace(/\s+/g, " ").trim().toLowerCase();
 
-  const isCorrect = useMemo(() => {
-    const userAnswer =
-      task.type === "sentence_shuffle"
-        ? constructed.join(" ")
-        : selected || "";
-    return normalize(userAnswer) === normalize(task.correctAnswer || "");
-  }, [constructed, selected, task]);
+  const isCorrect = verdict?.correct ?? false;
+  const correctAnswer = verdict?.answer ?? "";
+
+  const submitAnswer = async () => {
+    try {
+      const { data } = await apiPost("/api/submit-result", {
+        task_id: task.id,
+        answer: task.type === "sentence_shuffle" ? "" : selected || "",
+        answers: task.type === "sentence_shuffle" ? constructed : [],
+      });
+      setVerdict({ correct: !!data?.correct, answer: data?.correctAnswer ?? "" });
+      setAnswered(true);
+    } catch (error) {
+      console.error("Ошибка при проверке ответа:", error);
+    }
+  };
 
   const renderSentence = (sentence: string) => {
     const parts = sentence.split("___");
@@ -177,6 +187,7 @@ const TaskRenderer: React.FC<Props> = ({ task, onAnswer, currentIndex, total })
 
   useEffect(() => {
     setSelected(null);
+    setVerdict(null);
     setAnswered(false);
     setConstructed([]);
     setCheckResult(null);
@@ -213,16 +224,16 @@ const TaskRenderer: React.FC<Props> = ({ task, onAnswer, currentIndex, total })
 
     if (task.type === 'asr_reading') {
       if (!checkResult) return;
-      onAnswer(checkResult === 'correct');
+      onAnswer(checkResult === 'correct', "");
       setAnswered(false);
       setCheckResult(null);
       return;
     }
     else {
       if (!answered) {
-        setAnswered(true);
+        await submitAnswer();
       } else {
-        onAnswer(isCorrect);
+        onAnswer(isCorrect, task.type === "sentence_shuffle" ? constructed : selected || "");
         setAnswered(false);
       }
     }
@@ -360,7 +371,7 @@ const TaskRenderer: React.FC<Props> = ({ task, onAnswer, currentIndex, total })
                   const isSelected =
                     selected?.trim().toLowerCase() === option.trim().toLowerCase();
                   const isThisCorrect =
-                    task.correctAnswer?.trim().toLowerCase() === option.trim().toLowerCase();
+                    normalize(correctAnswer) === normalize(option);
 
                   return (
                     <TouchableOpacity
@@ -385,7 +396,7 @@ const TaskRenderer: React.FC<Props> = ({ task, onAnswer, currentIndex, total })
               <View style={styles.answerCard}>
                 <Text style={styles.cardTitle}>Правильный ответ:</Text>
                 <Text style={styles.cardSentence}>
-                  {task.correctAnswer?.trim().charAt(0).toUpperCase() + task.correctAnswer?.trim().slice(1) || "(пусто)"}
+                  {correctAnswer.trim().charAt(0).toUpperCase() + correctAnswer.trim().slice(1) || "(пусто)"}
                 </Text>
               </View>
             )}
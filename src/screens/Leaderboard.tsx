@@ -29,7 +29,7 @@ interface LeaderboardUser {
   id: number;
   name: string;
   avatar: string;
-  treeXp: number;
+  score: number;
   position: number;
   isCurrent: boolean;
 }
@@ -51,9 +51,10 @@ const LeaderboardScreen = () => {
           headers: { Authorization: `Bearer ${token}` },
         });
         const data = await response.json();
-        setUsers(data.slice(0, 50));
-        const current = data.find((u: LeaderboardUser) => u.isCurrent);
-        if (current && current.position > 50) setCurrentUser(current);
+        const entries: LeaderboardUser[] = data.entries ?? [];
+        setUsers(entries);
+        const current: LeaderboardUser | null = data.me ?? null;
+        setCurrentUser(current && !entries.some((u) => u.isCurrent) ? current : null);
       } catch (err) {
         console.error("Ошибка загрузки таблицы лидеров:", err);
       }
@@ -89,7 +90,7 @@ const LeaderboardScreen = () => {
               <View key={topThree[1].id} style={styles.podiumItem}>
                 <Image source={getAvatarUrl(topThree[1].avatar)} style={styles.podiumAvatar} />
                 <Text style={styles.podiumName}>{topThree[1].name}</Text>
-                <Text style={styles.podiumScore}>{topThree[1].treeXp} баллов</Text>
+                <Text style={styles.podiumScore}>{topThree[1].score} баллов</Text>
                 <View style={[styles.podiumBase, styles.secondPlace]}>
                   <Text style={styles.podiumNumber}>2</Text>
                 </View>
@@ -99,7 +100,7 @@ const LeaderboardScreen = () => {
               <View key={topThree[0].id} style={styles.podiumItem}>
                 <Image source={getAvatarUrl(topThree[0].avatar)} style={styles.podiumAvatar} />
                 <Text style={styles.podiumName}>{topThree[0].name}</Text>
-                <Text style={styles.podiumScore}>{topThree[0].treeXp} баллов</Text>
+                <Text style={styles.podiumScore}>{topThree[0].score} баллов</Text>
                 <View style={[styles.podiumBase, styles.firstPlace]}>
                   <Text style={styles.podiumNumber}>1</Text>
                 </View>
@@ -109,7 +110,7 @@ const LeaderboardScreen = () => {
               <View key={topThree[2].id} style={styles.podiumItem}>
                 <Image source={getAvatarUrl(topThree[2].avatar)} style={styles.podiumAvatar} />
                 <Text style={styles.podiumName}>{topThree[2].name}</Text>
-                <Text style={styles.podiumScore}>{topThree[2].treeXp} баллов</Text>
+                <Text style={styles.podiumScore}>{topThree[2].score} баллов</Text>
                 <View style={[styles.podiumBase, styles.thirdPlace]}>
                   <Text style={styles.podiumNumber}>3</Text>
                 </View>
@@ -148,7 +149,7 @@ const LeaderboardScreen = () => {
                 <Image source={getAvatarUrl(user.avatar)} style={styles.cardAvatar} />
                 <View style={styles.userInfo}>
                   <Text style={styles.userName}>{user.name}</Text>
-                  <Text style={styles.userStats}>{user.treeXp} баллов</Text>
+                  <Text style={styles.userStats}>{user.score} баллов</Text>
                 </View>
               </View>
             ))}
@@ -167,7 +168,7 @@ const LeaderboardScreen = () => {
                   <Image source={getAvatarUrl(currentUser.avatar)} style={styles.cardAvatar} />
                   <View style={styles.userInfo}>
                     <Text style={styles.userName}>{currentUser.name}</Text>
-                    <Text style={styles.userStats}>{currentUser.treeXp} баллов</Text>
+                    <Text style={styles.userStats}>{currentUser.score} баллов</Text>
                   </View>
                 </View>
               </>
@@ -6,18 +6,16 @@ import { useNavigation, useFocusEffect } from "@react-navigation/native";
 import { COLORS } from "../constants/colors";
 import { scaleFont, scaleSize } from "../constants/dimensions";
 import BackButton from "../components/BackButton";
-import { apiFetch, apiPost } from "../utils/api";
+import { apiFetch } from "../utils/api";
 import Button from "../components/Button";
 
 interface Task {
   id: string;
   word_id: number;
   sentence: string;
-  correctAnswer: string;
   translation: string;
   difficulty: string;
   options: string[];
-  originalSentence?: string;
   type?: string;
 }
 
@@ -77,11 +75,9 @@ const LearningScreen = () => {
           setTasks(
             data.map((t) => ({
               ...t,
-              correctAnswer: t.correct_answer.toLowerCase(),
               translationTarget: (t.translation_target || "").toLowerCase(),
               type: t.type,
               text: t.text || "",
-              originalSentence: t.sentence.replace("___", t.correct_answer),
             }))
           );
         }
@@ -109,15 +105,12 @@ const LearningScreen = () => {
   );
 
 
-  const handleAnswer = async (isCorrect: boolean) => {
+  const handleAnswer = async (isCorrect: boolean, answer: string | string[]) => {
     const currentTask = tasks[currentIndex];
 
     try {
-      const response = await apiPost("/api/submit-result", {
-        word_id: currentTask.word_id,
-        success: isCorrect,
-        task_type: currentTask.type,
-      });
+      // TaskRenderer already submitted the answer for server-side grading.
+      const response = await apiFetch("/api/profile");
 
       if (response?.data?.lives !== undefined) {
         const total = (response.data.lives ?? 0) + (response.data.bonusLives ?? 0);
@@ -52,7 +52,7 @@ export default function Login() {
     setErrorMessage("");
 
     try {
-      await AsyncStorage.removeItem("userToken");
+      await AsyncStorage.multiRemove(["userToken", "refreshToken"]);
 
       const { response, data } = await apiFetch("/auth/login", {
         method: "POST",
@@ -60,7 +60,10 @@ export default function Login() {
       });
 
       if (response.ok && data.token) {
-        await AsyncStorage.setItem("userToken", data.token);
+        await AsyncStorage.multiSet([
+          ["userToken", data.token],
+          ["refreshToken", data.refresh_token],
+        ]);
         navigation.reset({ index: 0, routes: [{ name: "MainScreen" }] });
       } else if (response.status === 403) {
         setErrorMessage("Сессия истекла. Войдите заново");
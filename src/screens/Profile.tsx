@@ -17,6 +17,7 @@ import AnimatedProgressBar from "../components/Progress";
 import { API_URL } from "../constants/api";
 import { COLORS } from "../constants/colors";
 import { scaleSize } from "../constants/dimensions";
+import { apiPost } from "../utils/api";
 import { useTypedNavigation } from "../hooks/useTypedNavigation";
 import { getAvatarUrl } from "../utils/avatar";
 import { useFocusEffect } from "@react-navigation/native";
@@ -77,7 +78,8 @@ const ProfileScreen = () => {
   );
 
   const handleLogout = async () => {
-    await AsyncStorage.removeItem("userToken");
+    await apiPost("/auth/logout", {}).catch(() => null);
+    await AsyncStorage.multiRemove(["userToken", "refreshToken"]);
     setMenuVisible(false);
     navigation.reset({ index: 0, routes: [{ name: "Welcome" }] });
   };
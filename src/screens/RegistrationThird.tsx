@@ -74,10 +74,14 @@ export default function RegistrationThird() {
         aimLevel,
         time,
         avatar: "",
+        timezone: Intl.DateTimeFormat().resolvedOptions().timeZone,
       });
 
       if (response.ok && data.token) {
-        await AsyncStorage.setItem("userToken", data.token);
+        await AsyncStorage.multiSet([
+          ["userToken", data.token],
+          ["refreshToken", data.refresh_token],
+        ]);
         await AsyncStorage.multiSet([
           ["reg_email", data.email],
           ["reg_name", data.name],
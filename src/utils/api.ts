@@ -1,35 +1,61 @@
 import AsyncStorage from "@react-native-async-storage/async-storage";
 import { API_URL } from "../constants/api";
 
-export const apiFetch = async (endpoint: string, options: RequestInit = {}) => {
-  const token = await AsyncStorage.getItem("userToken");
-
-  const response = await fetch(`${API_URL}${endpoint}`, {
-    ...options,
-    headers: {
-      "Content-Type": "application/json",
-      ...(token && { Authorization: `Bearer ${token}` }),
-      ...options.headers,
-    },
+const refreshSession = async (): Promise<boolean> => {
+  const refreshToken = await AsyncStorage.getItem("refreshToken");
+  if (!refreshToken) return false;
+
+  const response = await fetch(`${API_URL}/auth/refresh`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ refresh_token: refreshToken }),
   });
+  if (!response.ok) {
+    await AsyncStorage.multiRemove(["userToken", "refreshToken"]);
+    return false;
+  }
+
+  const data = await response.json();
+  await AsyncStorage.multiSet([
+    ["userToken", data.token],
+    ["refreshToken", data.refresh_token],
+  ]);
+  return true;
+};
+
+const authorizedFetch = async (endpoint: string, options: RequestInit = {}) => {
+  const send = async () => {
+    const token = await AsyncStorage.getItem("userToken");
+    return fetch(`${API_URL}${endpoint}`, {
+      ...options,
+      headers: {
+        "Content-Type": "application/json",
+        ...(token && { Authorization: `Bearer ${token}` }),
+        ...options.headers,
+      },
+    });
+  };
+
+  let response = await send();
+  if (response.status === 401 && !endpoint.startsWith("/auth/") && (await refreshSession())) {
+    response = await send();
+  }
+  return response;
+};
+
+export const apiFetch = async (endpoint: string, options: RequestInit = {}) => {
+  const response = await authorizedFetch(endpoint, options);
 
   const data = await response.json();
   return { response, data };
 };
 
 export const apiPost = async (endpoint: string, body: object) => {
-  const token = await AsyncStorage.getItem("userToken");
-
-  const response = await fetch(`${API_URL}${endpoint}`, {
+  const response = await authorizedFetch(endpoint, {
     method: "POST",
-    headers: {
-      "Content-Type": "application/json",
-      ...(token && { Authorization: `Bearer ${token}` }),
-    },
     body: JSON.stringify(body),
   });
 
   const data = await response.json();
   return { response, data };
 };
-  
\ No newline at end of file